@@ -143,13 +143,12 @@ func ReadableBool(value bool) string {
 	return "No"
 }
 
-// ReadableLevel formats a log level in a consistent way
+// ReadableLevel formats a log level name in a consistent way, using the same
+// names as the Level type (e.g. "warn" becomes "WARNING")
 func ReadableLevel(level string) string {
-	level = strings.ToUpper(level)
-	switch level {
-	case "DEBUG", "INFO", "WARN", "ERROR", "FATAL":
-		return level
-	default:
+	parsed, err := ParseLevel(level)
+	if err != nil {
 		return "UNKNOWN"
 	}
+	return strings.ToUpper(parsed.String())
 }
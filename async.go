@@ -0,0 +1,253 @@
+package ulog
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what an asynchronous logger does when its queue is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes the caller wait until the queue has room. No record is lost.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropNewest discards the record being logged
+	OverflowDropNewest
+
+	// OverflowDropOldest discards the oldest queued record to make room for the new one
+	OverflowDropOldest
+)
+
+// String returns the name of the policy
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDropNewest:
+		return "drop-newest"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	}
+	return "unknown"
+}
+
+// DefaultAsyncBufferSize is the queue size used when AsyncOptions.BufferSize is not set
+const DefaultAsyncBufferSize = 1024
+
+// AsyncOptions configures asynchronous logging
+type AsyncOptions struct {
+	// BufferSize is the number of records that can wait in the queue. Defaults to DefaultAsyncBufferSize.
+	BufferSize int
+
+	// Overflow is what happens when the queue is full. Defaults to OverflowBlock.
+	Overflow OverflowPolicy
+}
+
+// asyncEntry is a queued record with the settings it is rendered with
+type asyncEntry struct {
+	r  *Record
+	st settings
+}
+
+// asyncQueue is a bounded FIFO of records written to the sinks by a background goroutine
+type asyncQueue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	entries  []asyncEntry // ring buffer
+	head     int
+	n        int
+	policy   OverflowPolicy
+	closed   bool
+	queued   uint64         // records accepted so far
+	handled  uint64         // records written or dropped from the queue so far
+	dropped  *atomic.Uint64 // records lost to the overflow policy, shared by the queues of a core
+	progress chan struct{}  // closed and replaced whenever handled grows
+	done     chan struct{}  // closed when the worker has exited
+}
+
+// newAsyncQueue starts a queue writing to the sinks of c
+func newAsyncQueue(c *core, opts AsyncOptions) *asyncQueue {
+	size := opts.BufferSize
+	if size <= 0 {
+		size = DefaultAsyncBufferSize
+	}
+	q := &asyncQueue{
+		entries:  make([]asyncEntry, size),
+		policy:   opts.Overflow,
+		dropped:  &c.dropped,
+		progress: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	go q.run(c)
+	return q
+}
+
+// push queues a record according to the overflow policy. It returns false when
+// the queue is closed and the record must be written synchronously.
+func (q *asyncQueue) push(e asyncEntry) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.n == len(q.entries) && !q.closed {
+		switch q.policy {
+		case OverflowDropNewest:
+			q.dropped.Add(1)
+			return true
+		case OverflowDropOldest:
+			q.entries[q.head] = asyncEntry{}
+			q.head = (q.head + 1) % len(q.entries)
+			q.n--
+			q.dropped.Add(1)
+			q.advance(1)
+		default:
+			q.cond.Wait()
+		}
+	}
+	if q.closed {
+		return false
+	}
+	q.entries[(q.head+q.n)%len(q.entries)] = e
+	q.n++
+	q.queued++
+	q.cond.Broadcast()
+	return true
+}
+
+// advance records that n more records were handled. The caller must hold q.mu.
+func (q *asyncQueue) advance(n int) {
+	q.handled += uint64(n)
+	close(q.progress)
+	q.progress = make(chan struct{})
+}
+
+// run writes queued records in batches until the queue is closed and empty
+func (q *asyncQueue) run(c *core) {
+	defer close(q.done)
+	var batch []asyncEntry
+	for {
+		q.mu.Lock()
+		for q.n == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if q.n == 0 {
+			q.mu.Unlock()
+			return
+		}
+		batch = batch[:0]
+		for ; q.n > 0; q.n-- {
+			batch = append(batch, q.entries[q.head])
+			q.entries[q.head] = asyncEntry{}
+			q.head = (q.head + 1) % len(q.entries)
+		}
+		q.cond.Broadcast()
+		q.mu.Unlock()
+
+		for _, e := range batch {
+			c.dispatch(e.r, e.st)
+		}
+
+		q.mu.Lock()
+		q.advance(len(batch))
+		q.mu.Unlock()
+	}
+}
+
+// flush waits until every record queued before the call has been written
+func (q *asyncQueue) flush(ctx context.Context) error {
+	q.mu.Lock()
+	target := q.queued
+	for {
+		if q.handled >= target {
+			q.mu.Unlock()
+			return nil
+		}
+		progress := q.progress
+		q.mu.Unlock()
+		select {
+		case <-progress:
+		case <-q.done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		q.mu.Lock()
+	}
+}
+
+// close stops accepting records and waits until the queued ones are written
+func (q *asyncQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+	<-q.done
+}
+
+// SetAsync makes the logger tree, the logger with its parents and all their
+// children, queue records and write them to the sinks from a background
+// goroutine, so that logging calls do not wait for I/O.
+// A previous queue is drained first, so records stay in order; logging calls
+// block until it is. Call Flush or Close before the program exits to make sure
+// queued records are written.
+func (l *Logger) SetAsync(opts AsyncOptions) {
+	l.core.asyncMu.Lock()
+	defer l.core.asyncMu.Unlock()
+	l.core.closeAsync()
+	q := newAsyncQueue(l.core, opts)
+	l.core.mu.Lock()
+	l.core.async = q
+	l.core.mu.Unlock()
+}
+
+// closeAsync drains and removes the asynchronous queue, if any. The queue is
+// closed before it is removed, so that records logged meanwhile wait for the
+// queued ones instead of overtaking them. The caller must hold c.asyncMu.
+func (c *core) closeAsync() {
+	c.mu.RLock()
+	q := c.async
+	c.mu.RUnlock()
+	if q == nil {
+		return
+	}
+	q.close()
+	c.mu.Lock()
+	c.async = nil
+	c.mu.Unlock()
+}
+
+// Flush waits until every record logged before the call has been written to the
+// sinks, or until ctx is done. It returns immediately for a synchronous logger.
+func (l *Logger) Flush(ctx context.Context) error {
+	l.core.flushHeld()
+	l.core.mu.RLock()
+	q := l.core.async
+	l.core.mu.RUnlock()
+	if q == nil {
+		return nil
+	}
+	return q.flush(ctx)
+}
+
+// Close writes the queued records and stops the background goroutine of an
+// asynchronous logger. Records logged afterwards are written synchronously.
+// The sinks' writers are not closed.
+func (l *Logger) Close() error {
+	l.core.flushHeld()
+	l.core.asyncMu.Lock()
+	defer l.core.asyncMu.Unlock()
+	l.core.closeAsync()
+	return nil
+}
+
+// Dropped returns the number of records discarded because the asynchronous queue was full
+func (l *Logger) Dropped() uint64 {
+	return l.core.dropped.Load()
+}
+
+// Flush waits until every record logged with the default logger has been written
+func Flush(ctx context.Context) error {
+	return DefaultLogger.Flush(ctx)
+}
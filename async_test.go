@@ -0,0 +1,61 @@
+package ulog
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetAsyncKeepsOrder(t *testing.T) {
+	var out recorder
+	l := testLogger(&out, WithEncoder(LogfmtEncoder{}), WithAsync(AsyncOptions{BufferSize: 8}))
+
+	const messages = 2000
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < messages; i++ {
+			l.Info(strconv.Itoa(i))
+		}
+	}()
+	for i := 0; ; i++ {
+		select {
+		case <-done:
+		default:
+			l.SetAsync(AsyncOptions{BufferSize: 1 + i%16})
+			time.Sleep(50 * time.Microsecond)
+			continue
+		}
+		break
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != messages {
+		t.Fatalf("got %d lines, want %d", len(lines), messages)
+	}
+	for i, line := range lines {
+		if want := "msg=" + strconv.Itoa(i) + " "; !strings.Contains(line+" ", want) {
+			t.Fatalf("line %d is %q, want %s", i, line, want)
+		}
+	}
+}
+
+func TestFlushWritesQueuedRecords(t *testing.T) {
+	var out recorder
+	l := testLogger(&out, WithEncoder(LogfmtEncoder{}), WithAsync(AsyncOptions{}))
+	defer l.Close()
+	for i := 0; i < 100; i++ {
+		l.Info("queued")
+	}
+	if err := l.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(out.String(), "msg=queued"); n != 100 {
+		t.Errorf("got %d records after Flush, want 100", n)
+	}
+}
@@ -0,0 +1,64 @@
+package ulog
+
+// BoxStyle is the set of characters used to draw the border of a box.
+// A style without a Horizontal character is borderless: the tag and timestamp
+// are printed on a header line and every content line is prefixed with Vertical.
+type BoxStyle struct {
+	TopLeft     string
+	TopRight    string
+	BottomLeft  string
+	BottomRight string
+	Horizontal  string
+	Vertical    string
+}
+
+// Built-in box styles
+var (
+	// BoxRounded draws boxes with rounded corners. It is the default style.
+	BoxRounded = BoxStyle{"╭", "╮", "╰", "╯", "─", "│"}
+
+	// BoxSquare draws boxes with square corners
+	BoxSquare = BoxStyle{"┌", "┐", "└", "┘", "─", "│"}
+
+	// BoxDouble draws boxes with double lines
+	BoxDouble = BoxStyle{"╔", "╗", "╚", "╝", "═", "║"}
+
+	// BoxHeavy draws boxes with thick lines
+	BoxHeavy = BoxStyle{"┏", "┓", "┗", "┛", "━", "┃"}
+
+	// BoxASCII draws boxes with plain ASCII characters, for consoles and CI logs
+	// that cannot display Unicode box drawing characters
+	BoxASCII = BoxStyle{"+", "+", "+", "+", "-", "|"}
+
+	// BoxNone draws no border: a "[TAG] 15:04:05" header followed by the indented content
+	BoxNone = BoxStyle{}
+)
+
+// borderless reports whether the style draws no border around the content
+func (b BoxStyle) borderless() bool {
+	return b.Horizontal == ""
+}
+
+// SetBoxStyle sets the characters used to draw boxes
+func (l *Logger) SetBoxStyle(style BoxStyle) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.s.style = style
+	l.set |= setStyle
+}
+
+// WithBoxStyle returns a child logger that draws boxes with the given style.
+// It can be used to change the style of a single call:
+//
+//	logger.WithBoxStyle(ulog.BoxASCII).Warning("Plain ASCII box")
+func (l *Logger) WithBoxStyle(style BoxStyle) *Logger {
+	child := l.clone()
+	child.s.style = style
+	child.set |= setStyle
+	return child
+}
+
+// SetBoxStyle sets the box style of the default logger
+func SetBoxStyle(style BoxStyle) {
+	DefaultLogger.SetBoxStyle(style)
+}
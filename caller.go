@@ -0,0 +1,101 @@
+package ulog
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// CallerMode selects how the call site of a message is annotated
+type CallerMode int
+
+const (
+	// CallerOff disables the annotation. It is the default.
+	CallerOff CallerMode = iota
+
+	// CallerFile annotates messages with the file name and line, e.g. "main.go:42"
+	CallerFile
+
+	// CallerFunction also adds the function name, e.g. "main.go:42 main.run"
+	CallerFunction
+)
+
+// callerDepth is the number of frames between runtime.Callers in callerAt and
+// the code calling an exported logging function: callerAt, newRecord, log, logf
+// or logCtx, and the exported function. Every exported logging function, including
+// the package-level ones, must call one of them directly to keep it correct.
+const callerDepth = 4
+
+// Caller is the call site of a log message
+type Caller struct {
+	File     string // file name without its directory
+	Line     int
+	Function string // package-qualified function name, empty unless CallerFunction is set
+}
+
+// String returns the call site as "file.go:42", followed by the function name when it is known
+func (c *Caller) String() string {
+	s := c.Location()
+	if c.Function != "" {
+		s += " " + c.Function
+	}
+	return s
+}
+
+// Location returns the call site as "file.go:42"
+func (c *Caller) Location() string {
+	return c.File + ":" + strconv.Itoa(c.Line)
+}
+
+// callerAt returns the call site skip frames above its caller, or nil when the caller annotation is off
+func callerAt(skip int, mode CallerMode) *Caller {
+	if mode == CallerOff {
+		return nil
+	}
+	var pcs [1]uintptr
+	if runtime.Callers(skip+1, pcs[:]) == 0 {
+		return nil
+	}
+	return callerFromPC(pcs[0], mode)
+}
+
+// callerFromPC resolves a program counter, as returned by runtime.Callers, to a Caller
+func callerFromPC(pc uintptr, mode CallerMode) *Caller {
+	if mode == CallerOff || pc == 0 {
+		return nil
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.File == "" {
+		return nil
+	}
+	c := &Caller{File: filepath.Base(frame.File), Line: frame.Line}
+	if mode == CallerFunction {
+		c.Function = shortFunction(frame.Function)
+	}
+	return c
+}
+
+// shortFunction strips the import path from a function name, keeping the package
+// name: "github.com/acme/api.(*Server).Run" becomes "api.(*Server).Run"
+func shortFunction(name string) string {
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// SetCaller sets how messages are annotated with the file, line and optionally
+// function that logged them. The annotation is shown next to the timestamp in
+// boxes and as "caller" and "func" fields by structured encoders.
+func (l *Logger) SetCaller(mode CallerMode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.s.caller = mode
+	l.set |= setCaller
+}
+
+// SetCaller sets the caller annotation of the default logger
+func SetCaller(mode CallerMode) {
+	DefaultLogger.SetCaller(mode)
+}
@@ -0,0 +1,37 @@
+package ulog
+
+import "strings"
+
+// Named returns a child logger whose tag is the parent's tag with name
+// appended, separated by a dot, so that nested components are tagged
+// "api.db". The child inherits its parent's level, fields, settings, hooks and
+// context extractors; the Set calls that change them, AddHook and
+// AddContextExtractor apply to the child and its own children only.
+//
+// A logger created by NewLogger and all the children derived from it form a
+// logger tree, which shares one set of sinks and one writing pipeline. The
+// methods that configure them act on the whole tree whichever logger they are
+// called on: SetOutput, SetErrorOutput, AddSink, SetSinks, SetErrorHandler,
+// SetAsync, SetSampling, SetCollapse and SetExitFunc, as well as Flush and Close.
+//
+//	api := logger.Named("api")
+//	db := api.Named("db")
+//	db.Info("connected") // tagged "api.db"
+func (l *Logger) Named(name string) *Logger {
+	child := l.clone()
+	child.name = strings.Trim(name, ".")
+	return child
+}
+
+// joinTag appends name to a dotted tag
+func joinTag(tag, name string) string {
+	if tag == "" {
+		return name
+	}
+	return tag + "." + name
+}
+
+// Named returns a child of the default logger with the given name appended to its tag
+func Named(name string) *Logger {
+	return DefaultLogger.Named(name)
+}
@@ -0,0 +1,138 @@
+package ulog
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// collapser merges identical consecutive records into one record with a repeat
+// count. The pending record is written when a different record arrives, when
+// the timeout since its first occurrence elapses, or when the logger is flushed.
+type collapser struct {
+	timeout time.Duration
+	next    func(*Record, settings)
+	mu      sync.Mutex
+	pending *Record
+	st      settings
+	timer   *time.Timer
+}
+
+// add collapses the record into the pending one or makes it the new pending record
+func (c *collapser) add(r *Record, st settings) {
+	c.mu.Lock()
+	if p := c.pending; p != nil && sameRecord(p, r) {
+		p.Repeat++
+		p.LastTime = r.Time
+		c.st = st
+		c.mu.Unlock()
+		return
+	}
+	prev, prevSt := c.take()
+	// Panic and Fatal records are never held back
+	hold := r.Level < LevelPanic
+	if hold {
+		pending := r
+		pending.Repeat = 1
+		c.pending, c.st = pending, st
+		c.timer = time.AfterFunc(c.timeout, func() { c.flushPending(pending) })
+	}
+	c.mu.Unlock()
+
+	if prev != nil {
+		c.next(prev, prevSt)
+	}
+	if !hold {
+		c.next(r, st)
+	}
+}
+
+// take removes and returns the pending record. The caller must hold c.mu.
+func (c *collapser) take() (*Record, settings) {
+	r, st := c.pending, c.st
+	if r == nil {
+		return nil, st
+	}
+	c.timer.Stop()
+	c.pending, c.timer = nil, nil
+	return r, st
+}
+
+// flushPending writes r if it is still the pending record
+func (c *collapser) flushPending(r *Record) {
+	c.mu.Lock()
+	if c.pending != r {
+		c.mu.Unlock()
+		return
+	}
+	r, st := c.take()
+	c.mu.Unlock()
+	c.next(r, st)
+}
+
+// flush writes the pending record, if any
+func (c *collapser) flush() {
+	c.mu.Lock()
+	r, st := c.take()
+	c.mu.Unlock()
+	if r != nil {
+		c.next(r, st)
+	}
+}
+
+// sameRecord reports whether b repeats a: same level, tag, message and fields
+func sameRecord(a, b *Record) bool {
+	if a.Level != b.Level || a.Tag != b.Tag || a.Message != b.Message || len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	if len(a.Fields) > 0 && &a.Fields[0] == &b.Fields[0] {
+		return true
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Key != b.Fields[i].Key || !reflect.DeepEqual(a.Fields[i].Value, b.Fields[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// repeatNote describes a collapsed record, e.g. "×37 (last at 15:04:09)",
+// or returns "" for a single record
+func repeatNote(r *Record, ascii bool) string {
+	if r.Repeat < 2 {
+		return ""
+	}
+	times := "×"
+	if ascii {
+		times = "x"
+	}
+	if r.LastTime.IsZero() {
+		return times + strconv.Itoa(r.Repeat)
+	}
+	return times + strconv.Itoa(r.Repeat) + " (last at " + r.LastTime.Format("15:04:05") + ")"
+}
+
+// SetCollapse collapses identical consecutive messages of the logger tree, the
+// logger with its parents and all their children, into a single message showing
+// the number of repeats, e.g. "×37 (last at 15:04:09)". A message is held back
+// until a different one is logged, timeout has passed since it was first logged,
+// or the logger is flushed. A zero timeout turns collapsing off.
+func (l *Logger) SetCollapse(timeout time.Duration) {
+	var c *collapser
+	if timeout > 0 {
+		c = &collapser{timeout: timeout, next: l.core.sample}
+	}
+	l.core.mu.Lock()
+	old := l.core.collapser
+	l.core.collapser = c
+	l.core.mu.Unlock()
+	if old != nil {
+		old.flush()
+	}
+}
+
+// SetCollapse sets the repeated-message collapsing of the default logger
+func SetCollapse(timeout time.Duration) {
+	DefaultLogger.SetCollapse(timeout)
+}
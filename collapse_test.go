@@ -0,0 +1,149 @@
+package ulog
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// collapseEncoders are the console encoders that show the repeat count, with a
+// function splitting their output into one string per record
+var collapseEncoders = []struct {
+	name  string
+	enc   Encoder
+	split func(t *testing.T, out string) []string
+}{
+	{"box", BoxEncoder{}, func(t *testing.T, out string) []string {
+		var entries []string
+		for _, box := range splitBoxes(t, out) {
+			checkBox(t, box)
+			entries = append(entries, strings.Join(box, "\n"))
+		}
+		return entries
+	}},
+	{"compact", CompactEncoder{}, func(t *testing.T, out string) []string {
+		if out == "" {
+			return nil
+		}
+		return strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	}},
+}
+
+// checkEntries fails unless each entry contains the message and, when it is not
+// empty, the repeat count of the corresponding want item
+func checkEntries(t *testing.T, entries []string, want ...[2]string) {
+	t.Helper()
+	if len(entries) != len(want) {
+		t.Fatalf("got %d records, want %d:\n%s", len(entries), len(want), strings.Join(entries, "\n"))
+	}
+	for i, w := range want {
+		msg, count := w[0], w[1]
+		if !strings.Contains(entries[i], msg) {
+			t.Errorf("record %d does not contain %q:\n%s", i, msg, entries[i])
+		}
+		if count == "" && strings.Contains(entries[i], "×") {
+			t.Errorf("record %d has a repeat count:\n%s", i, entries[i])
+		}
+		if count != "" && !strings.Contains(entries[i], count+" (last at ") {
+			t.Errorf("record %d does not show %q:\n%s", i, count, entries[i])
+		}
+	}
+}
+
+func TestCollapseRepeats(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	for _, e := range collapseEncoders {
+		t.Run(e.name, func(t *testing.T) {
+			var out recorder
+			l := testLogger(&out, WithEncoder(e.enc), WithCollapse(time.Hour))
+			for i := 0; i < 3; i++ {
+				l.Info("connection refused", "DB")
+			}
+			if out.String() != "" {
+				t.Fatalf("repeated message written before it was flushed: %q", out.String())
+			}
+
+			// A different message flushes the collapsed one; a single message has no count
+			l.Info("connected", "DB")
+			l.Info("serving")
+			l.Info("serving", "HTTP")
+			checkEntries(t, e.split(t, out.String()), [2]string{"connection refused", "×3"}, [2]string{"connected", ""}, [2]string{"serving", ""})
+
+			// Flush writes the held message
+			if err := l.Flush(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			entries := e.split(t, out.String())
+			checkEntries(t, entries[3:], [2]string{"serving", ""})
+		})
+	}
+}
+
+func TestCollapseDifferentFields(t *testing.T) {
+	var out recorder
+	l := testLogger(&out, WithEncoder(CompactEncoder{}), WithCollapse(time.Hour))
+	l.With("attempt", 1).Info("retry")
+	l.With("attempt", 1).Info("retry")
+	l.With("attempt", 2).Info("retry")
+	l.Warning("retry")
+	l.Close()
+	checkEntries(t, collapseEncoders[1].split(t, out.String()),
+		[2]string{"attempt=1", "×2"}, [2]string{"attempt=2", ""}, [2]string{"retry", ""})
+}
+
+func TestCollapseTimeout(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	for _, e := range collapseEncoders {
+		t.Run(e.name, func(t *testing.T) {
+			var out recorder
+			l := testLogger(&out, WithEncoder(e.enc), WithCollapse(20*time.Millisecond))
+			l.Error("disk full")
+			l.Error("disk full")
+			deadline := time.Now().Add(5 * time.Second)
+			for out.String() == "" && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			checkEntries(t, e.split(t, out.String()), [2]string{"disk full", "×2"})
+		})
+	}
+}
+
+func TestCollapseClose(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	for _, e := range collapseEncoders {
+		t.Run(e.name, func(t *testing.T) {
+			var out recorder
+			l := testLogger(&out, WithEncoder(e.enc), WithCollapse(time.Hour), WithAsync(AsyncOptions{}))
+			for i := 0; i < 5; i++ {
+				l.Warning("slow request")
+			}
+			if err := l.Close(); err != nil {
+				t.Fatal(err)
+			}
+			checkEntries(t, e.split(t, out.String()), [2]string{"slow request", "×5"})
+		})
+	}
+}
+
+func TestCollapseFatalNotHeld(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	for _, e := range collapseEncoders {
+		t.Run(e.name, func(t *testing.T) {
+			var out recorder
+			var codes []int
+			l := testLogger(&out, WithEncoder(e.enc), WithCollapse(time.Hour))
+			l.SetExitFunc(func(code int) { codes = append(codes, code) })
+			l.Error("giving up")
+			l.Error("giving up")
+			l.Fatal("shutting down")
+			l.Fatal("shutting down")
+
+			checkEntries(t, e.split(t, out.String()),
+				[2]string{"giving up", "×2"}, [2]string{"shutting down", ""}, [2]string{"shutting down", ""})
+			if len(codes) != 2 || codes[0] != 1 {
+				t.Errorf("exit codes = %v, want [1 1]", codes)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package ulog
+
+import (
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// ColorProfile is the color capability of an output
+type ColorProfile int
+
+// Color profiles from least to most capable. ProfileAuto detects the profile
+// of each output writer separately.
+const (
+	ProfileAuto ColorProfile = iota
+	ProfileNoColor
+	ProfileANSI
+	ProfileANSI256
+	ProfileTrueColor
+)
+
+// String returns the name of the profile
+func (p ColorProfile) String() string {
+	switch p {
+	case ProfileAuto:
+		return "auto"
+	case ProfileNoColor:
+		return "none"
+	case ProfileANSI:
+		return "ansi"
+	case ProfileANSI256:
+		return "ansi256"
+	case ProfileTrueColor:
+		return "truecolor"
+	}
+	return "unknown"
+}
+
+// DetectColorProfile returns the color profile of the given writer.
+//
+// Colors are disabled when NO_COLOR is set, when CLICOLOR=0, when TERM=dumb or
+// when w is not a terminal. FORCE_COLOR (or CLICOLOR_FORCE) enables colors even
+// for files and pipes; FORCE_COLOR=2 and FORCE_COLOR=3 request 256 colors and
+// true color, and FORCE_COLOR=0 disables colors. Otherwise the profile is taken
+// from COLORTERM and TERM.
+func DetectColorProfile(w io.Writer) ColorProfile {
+	if os.Getenv("NO_COLOR") != "" {
+		return ProfileNoColor
+	}
+
+	forced := ProfileAuto
+	if force, ok := os.LookupEnv("FORCE_COLOR"); ok {
+		switch strings.ToLower(force) {
+		case "0", "false", "no", "off":
+			return ProfileNoColor
+		case "2":
+			forced = ProfileANSI256
+		case "3":
+			forced = ProfileTrueColor
+		default:
+			forced = ProfileANSI
+		}
+	} else if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		forced = ProfileANSI
+	}
+
+	if forced == ProfileAuto {
+		if !isTerminal(w) || !enableVirtualTerminal(w) {
+			return ProfileNoColor
+		}
+		if os.Getenv("CLICOLOR") == "0" {
+			return ProfileNoColor
+		}
+	}
+
+	profile := envColorProfile()
+	if profile < forced {
+		profile = forced
+	}
+	return profile
+}
+
+// envColorProfile returns the color profile advertised by the terminal through
+// the COLORTERM and TERM environment variables
+func envColorProfile() ColorProfile {
+	term := strings.ToLower(os.Getenv("TERM"))
+	if term == "dumb" {
+		return ProfileNoColor
+	}
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ProfileTrueColor
+	}
+	switch {
+	case strings.Contains(term, "truecolor"), strings.Contains(term, "direct"):
+		return ProfileTrueColor
+	case strings.Contains(term, "256color"):
+		return ProfileANSI256
+	case os.Getenv("WT_SESSION") != "":
+		// Windows Terminal supports true color but does not set COLORTERM
+		return ProfileTrueColor
+	case term == "" && runtime.GOOS == "windows":
+		return ProfileANSI256
+	}
+	return ProfileANSI
+}
+
+// isTerminal reports whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// ansiPalette holds typical RGB values of the 16 basic ANSI colors
+var ansiPalette = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels are the channel values of the 6x6x6 color cube of the 256-color palette
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// rgb returns the RGB value of a basic, 256-palette or true color
+func (c Color) rgb() (uint8, uint8, uint8) {
+	switch {
+	case c.kind == colorRGB:
+		return c.r, c.g, c.b
+	case c.index < 16:
+		p := ansiPalette[c.index]
+		return p[0], p[1], p[2]
+	case c.index < 232:
+		i := c.index - 16
+		return cubeLevels[i/36], cubeLevels[(i/6)%6], cubeLevels[i%6]
+	}
+	grey := 8 + 10*(c.index-232)
+	return grey, grey, grey
+}
+
+// convert downgrades the color to one the profile can display
+func (c Color) convert(profile ColorProfile) Color {
+	switch {
+	case c.kind == colorDefault, profile == ProfileTrueColor:
+		return c
+	case profile == ProfileNoColor:
+		return ColorDefault
+	case profile == ProfileANSI256 && c.kind == colorRGB:
+		return Color256(nearest256(c.rgb()))
+	case profile == ProfileANSI && c.kind != colorBasic:
+		return basicColor(nearestANSI(c.rgb()))
+	}
+	return c
+}
+
+// nearest256 returns the 256-palette index closest to the RGB value,
+// choosing between the color cube and the grey ramp
+func nearest256(r, g, b uint8) uint8 {
+	ri, gi, bi := nearestCubeLevel(r), nearestCubeLevel(g), nearestCubeLevel(b)
+	cube := 16 + 36*ri + 6*gi + bi
+	cubeDist := colorDistance(r, g, b, cubeLevels[ri], cubeLevels[gi], cubeLevels[bi])
+
+	avg := (int(r) + int(g) + int(b)) / 3
+	greyIndex := 23
+	if avg < 238 {
+		greyIndex = (avg - 3) / 10
+		if greyIndex < 0 {
+			greyIndex = 0
+		}
+	}
+	grey := uint8(8 + 10*greyIndex)
+	if colorDistance(r, g, b, grey, grey, grey) < cubeDist {
+		return uint8(232 + greyIndex)
+	}
+	return cube
+}
+
+// nearestCubeLevel returns the index of the cube level closest to v
+func nearestCubeLevel(v uint8) uint8 {
+	best := uint8(0)
+	for i, level := range cubeLevels {
+		if absDiff(v, level) < absDiff(v, cubeLevels[best]) {
+			best = uint8(i)
+		}
+	}
+	return best
+}
+
+// nearestANSI returns the basic ANSI color closest to the RGB value
+func nearestANSI(r, g, b uint8) uint8 {
+	best, bestDist := uint8(0), -1
+	for i, p := range ansiPalette {
+		if d := colorDistance(r, g, b, p[0], p[1], p[2]); bestDist < 0 || d < bestDist {
+			best, bestDist = uint8(i), d
+		}
+	}
+	return best
+}
+
+func colorDistance(r1, g1, b1, r2, g2, b2 uint8) int {
+	dr, dg, db := int(r1)-int(r2), int(g1)-int(g2), int(b1)-int(b2)
+	return dr*dr + dg*dg + db*db
+}
+
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// convert downgrades the style's colors to ones the profile can display.
+// Without color support all styling is removed.
+func (s Style) convert(profile ColorProfile) Style {
+	if profile == ProfileNoColor {
+		return Style{}
+	}
+	s.Color = s.Color.convert(profile)
+	s.Background = s.Background.convert(profile)
+	return s
+}
+
+// convert downgrades the level theme to the profile
+func (lt LevelTheme) convert(profile ColorProfile) LevelTheme {
+	return LevelTheme{
+		Border:    lt.Border.convert(profile),
+		Tag:       lt.Tag.convert(profile),
+		Timestamp: lt.Timestamp.convert(profile),
+		Body:      lt.Body.convert(profile),
+	}
+}
+
+// SetColorProfile overrides the detected color profile of every output writer.
+// ProfileAuto restores detection, ProfileNoColor disables colors.
+func (l *Logger) SetColorProfile(profile ColorProfile) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.s.colorProfile = profile
+	l.set |= setColorProfile
+}
+
+// SetColorProfile overrides the color profile of the default logger
+func SetColorProfile(profile ColorProfile) {
+	DefaultLogger.SetColorProfile(profile)
+}
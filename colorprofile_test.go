@@ -0,0 +1,146 @@
+package ulog
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// colorEnv lists the environment variables read by DetectColorProfile
+var colorEnv = []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR", "CLICOLOR_FORCE", "TERM", "COLORTERM", "WT_SESSION"}
+
+// setColorEnv replaces the color environment variables with env for the rest of the test
+func setColorEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	for _, key := range colorEnv {
+		t.Setenv(key, "")
+		if value, ok := env[key]; ok {
+			os.Setenv(key, value)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+func TestDetectColorProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want ColorProfile
+	}{
+		{"not a terminal", map[string]string{"TERM": "xterm-256color"}, ProfileNoColor},
+		{"NO_COLOR", map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "3"}, ProfileNoColor},
+		{"FORCE_COLOR", map[string]string{"FORCE_COLOR": "1", "TERM": "xterm"}, ProfileANSI},
+		{"FORCE_COLOR empty", map[string]string{"FORCE_COLOR": "", "TERM": "xterm"}, ProfileANSI},
+		{"FORCE_COLOR true", map[string]string{"FORCE_COLOR": "true", "TERM": "xterm"}, ProfileANSI},
+		{"FORCE_COLOR 0", map[string]string{"FORCE_COLOR": "0", "TERM": "xterm-256color"}, ProfileNoColor},
+		{"FORCE_COLOR false", map[string]string{"FORCE_COLOR": "false", "CLICOLOR_FORCE": "1"}, ProfileNoColor},
+		{"FORCE_COLOR 2", map[string]string{"FORCE_COLOR": "2", "TERM": "xterm"}, ProfileANSI256},
+		{"FORCE_COLOR 3", map[string]string{"FORCE_COLOR": "3", "TERM": "xterm"}, ProfileTrueColor},
+		{"FORCE_COLOR below TERM", map[string]string{"FORCE_COLOR": "1", "TERM": "xterm-256color"}, ProfileANSI256},
+		{"FORCE_COLOR below COLORTERM", map[string]string{"FORCE_COLOR": "2", "TERM": "xterm", "COLORTERM": "truecolor"}, ProfileTrueColor},
+		{"FORCE_COLOR with TERM=dumb", map[string]string{"FORCE_COLOR": "2", "TERM": "dumb"}, ProfileANSI256},
+		{"CLICOLOR_FORCE", map[string]string{"CLICOLOR_FORCE": "1", "TERM": "xterm"}, ProfileANSI},
+		{"CLICOLOR_FORCE 0", map[string]string{"CLICOLOR_FORCE": "0", "TERM": "xterm"}, ProfileNoColor},
+		{"CLICOLOR_FORCE with CLICOLOR=0", map[string]string{"CLICOLOR_FORCE": "1", "CLICOLOR": "0", "TERM": "xterm"}, ProfileANSI},
+		{"CLICOLOR", map[string]string{"CLICOLOR": "1", "TERM": "xterm"}, ProfileNoColor},
+		{"TERM=dumb", map[string]string{"TERM": "dumb"}, ProfileNoColor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setColorEnv(t, tt.env)
+			if got := DetectColorProfile(&bytes.Buffer{}); got != tt.want {
+				t.Errorf("DetectColorProfile = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvColorProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want ColorProfile
+	}{
+		{"dumb", map[string]string{"TERM": "dumb", "COLORTERM": "truecolor"}, ProfileNoColor},
+		{"xterm", map[string]string{"TERM": "xterm"}, ProfileANSI},
+		{"256color", map[string]string{"TERM": "screen-256color"}, ProfileANSI256},
+		{"direct", map[string]string{"TERM": "xterm-direct"}, ProfileTrueColor},
+		{"COLORTERM truecolor", map[string]string{"TERM": "xterm", "COLORTERM": "truecolor"}, ProfileTrueColor},
+		{"COLORTERM 24bit", map[string]string{"TERM": "xterm-256color", "COLORTERM": "24bit"}, ProfileTrueColor},
+		{"Windows Terminal", map[string]string{"TERM": "xterm", "WT_SESSION": "1"}, ProfileTrueColor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setColorEnv(t, tt.env)
+			if got := envColorProfile(); got != tt.want {
+				t.Errorf("envColorProfile = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNearest256(t *testing.T) {
+	tests := []struct {
+		r, g, b uint8
+		want    uint8
+	}{
+		{0, 0, 0, 16},
+		{255, 255, 255, 231},
+		{255, 0, 0, 196},
+		{95, 135, 175, 67},
+		{100, 140, 170, 67},
+		{128, 128, 128, 244},
+		{238, 238, 238, 255},
+		{8, 8, 8, 232},
+	}
+	for _, tt := range tests {
+		if got := nearest256(tt.r, tt.g, tt.b); got != tt.want {
+			t.Errorf("nearest256(%d, %d, %d) = %d, want %d", tt.r, tt.g, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNearestANSI(t *testing.T) {
+	tests := []struct {
+		r, g, b uint8
+		want    uint8
+	}{
+		{0, 0, 0, 0},
+		{255, 255, 255, 15},
+		{200, 0, 0, 1},
+		{250, 10, 10, 9},
+		{0, 0, 230, 4},
+		{120, 120, 120, 8},
+		{0, 210, 200, 6},
+	}
+	for _, tt := range tests {
+		if got := nearestANSI(tt.r, tt.g, tt.b); got != tt.want {
+			t.Errorf("nearestANSI(%d, %d, %d) = %d, want %d", tt.r, tt.g, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestColorConvert(t *testing.T) {
+	red := ColorRGB(255, 0, 0)
+	tests := []struct {
+		name    string
+		c       Color
+		profile ColorProfile
+		want    Color
+	}{
+		{"truecolor kept", red, ProfileTrueColor, red},
+		{"rgb to 256", red, ProfileANSI256, Color256(196)},
+		{"rgb to ansi", red, ProfileANSI, ColorBrightRed},
+		{"256 kept", Color256(67), ProfileANSI256, Color256(67)},
+		{"256 to ansi", Color256(196), ProfileANSI, ColorBrightRed},
+		{"basic kept", ColorGreen, ProfileANSI, ColorGreen},
+		{"no color", red, ProfileNoColor, ColorDefault},
+		{"default kept", ColorDefault, ProfileANSI, ColorDefault},
+	}
+	for _, tt := range tests {
+		if got := tt.c.convert(tt.profile); got != tt.want {
+			t.Errorf("%s: convert = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
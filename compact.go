@@ -0,0 +1,103 @@
+package ulog
+
+import (
+	"bytes"
+	"strings"
+)
+
+// levelIcons are the symbols shown by CompactEncoder for each level
+var levelIcons = map[Level]string{
+	LevelDebug:   "•",
+	LevelInfo:    "ℹ",
+	LevelSuccess: "✔",
+	LevelOngoing: "↻",
+	LevelWarning: "⚠",
+	LevelError:   "✖",
+	LevelPanic:   "✖",
+	LevelFatal:   "✖",
+}
+
+// asciiLevelIcons replace levelIcons when the logger uses BoxASCII
+var asciiLevelIcons = map[Level]string{
+	LevelDebug:   "*",
+	LevelInfo:    "i",
+	LevelSuccess: "+",
+	LevelOngoing: "~",
+	LevelWarning: "!",
+	LevelError:   "x",
+	LevelPanic:   "X",
+	LevelFatal:   "X",
+}
+
+// CompactEncoder renders each record on a single line with the level's colors:
+//
+//	15:04:05 ✔ [TAG] message user=42
+//
+// The call site, when annotated, follows the timestamp, and the repeat count of
+// collapsed messages follows the message.
+// Additional message lines are indented under the first one. It uses the
+// logger's theme, timestamp setting and terminal width like BoxEncoder.
+type CompactEncoder struct{}
+
+// Encode implements Encoder
+func (CompactEncoder) Encode(buf *bytes.Buffer, r *Record) error {
+	h := hintsFor(r)
+	st := h.st
+	theme := st.theme.forLevel(r.Level).convert(h.profile)
+
+	icons := levelIcons
+	if st.style == BoxASCII {
+		icons = asciiLevelIcons
+	}
+	icon, ok := icons[r.Level]
+	if !ok {
+		icon = icons[LevelInfo]
+	}
+
+	// Prefix: timestamp, icon and tag
+	var prefix []string
+	if st.showTimestamp && !r.Time.IsZero() {
+		prefix = append(prefix, theme.Timestamp.paint(r.Time.Format("15:04:05")))
+	}
+	if r.Caller != nil {
+		prefix = append(prefix, theme.Timestamp.paint(r.Caller.String()))
+	}
+	prefix = append(prefix, theme.Tag.paint(icon))
+	if r.Tag != "" {
+		prefix = append(prefix, theme.Border.paint("[")+theme.Tag.paint(r.Tag)+theme.Border.paint("]"))
+	}
+	head := strings.Join(prefix, " ") + " "
+	indent := strings.Repeat(" ", displayWidth(head))
+
+	// Fields follow the message as key=value pairs
+	var fields []string
+	for _, f := range flattenFields(r.Fields) {
+		fields = append(fields, theme.Timestamp.paint(logfmtKey(f.Key)+"=")+logfmtValue(logfmtString(f.Value)))
+	}
+
+	inner := 0
+	if width := availableWidth(h.w, st.maxWidth); width > 0 {
+		inner = width - len(indent)
+	}
+	lines := strings.Split(r.Message, "\n")
+	if note := repeatNote(r, st.style == BoxASCII); note != "" {
+		lines[len(lines)-1] += " " + theme.Timestamp.paint(note)
+	}
+	if len(fields) > 0 {
+		lines[len(lines)-1] += " " + strings.Join(fields, " ")
+	}
+	first := true
+	for _, line := range lines {
+		for _, part := range wrapLine(line, inner) {
+			if first {
+				buf.WriteString(head)
+				first = false
+			} else {
+				buf.WriteString(indent)
+			}
+			buf.WriteString(theme.Body.paint(part))
+			buf.WriteByte('\n')
+		}
+	}
+	return nil
+}
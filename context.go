@@ -0,0 +1,148 @@
+package ulog
+
+import "context"
+
+// loggerKey is the context key of the logger stored by WithContext
+type loggerKey struct{}
+
+// WithContext returns a copy of ctx that carries the logger, typically a child
+// logger with request-scoped fields:
+//
+//	ctx = ulog.WithContext(ctx, logger.With("request_id", id))
+func WithContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by WithContext, or DefaultLogger
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey{}).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+	return DefaultLogger
+}
+
+// ContextExtractor returns fields taken from a context, such as a request or user ID.
+// It is called for every message logged with a context and may return nil.
+type ContextExtractor func(ctx context.Context) []Field
+
+// ContextValue returns an extractor that adds the value stored in the context
+// under key as a field named field, when it is present:
+//
+//	logger.AddContextExtractor(ulog.ContextValue(requestIDKey{}, "request_id"))
+func ContextValue(key any, field string) ContextExtractor {
+	return func(ctx context.Context) []Field {
+		if v := ctx.Value(key); v != nil {
+			return []Field{{Key: field, Value: v}}
+		}
+		return nil
+	}
+}
+
+// AddContextExtractor adds an extractor to the logger and its children, including
+// existing ones. The fields it returns are added to every message logged with a
+// context, after the logger's own fields. Extractors run in the order they were
+// added, those of a child's parents first.
+//
+// Extractors are kept for the lifetime of the logger, so add them once at
+// startup: adding one for every request, e.g. to FromContext(ctx) when the
+// context carries no logger of its own, grows the list of a shared logger forever.
+func (l *Logger) AddContextExtractor(fn ContextExtractor) {
+	if fn == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.s.extractors = append(l.s.extractors[:len(l.s.extractors):len(l.s.extractors)], fn)
+}
+
+// contextFields runs the logger's extractors on ctx
+func (l *Logger) contextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	var fields []Field
+	for _, fn := range l.settings().extractors {
+		fields = append(fields, fn(ctx)...)
+	}
+	return fields
+}
+
+// logCtx logs a message with the fields extracted from ctx. Like log, it must
+// be called directly by the exported functions for the caller annotation.
+func (l *Logger) logCtx(ctx context.Context, level Level, message string, tag []string) {
+	if !l.Enabled(level) {
+		return
+	}
+	r := l.newRecord(level, message, tag)
+	if fields := l.contextFields(ctx); len(fields) > 0 {
+		r.Fields = appendFields(r.Fields, fields...)
+	}
+	l.write(r)
+}
+
+// WarningContext logs a warning message with the fields extracted from ctx
+func (l *Logger) WarningContext(ctx context.Context, message string, tag ...string) {
+	l.logCtx(ctx, LevelWarning, message, tag)
+}
+
+// MessageContext logs a message with the fields extracted from ctx
+func (l *Logger) MessageContext(ctx context.Context, message string, tag ...string) {
+	l.logCtx(ctx, LevelDebug, message, tag)
+}
+
+// InfoContext logs an info message with the fields extracted from ctx
+func (l *Logger) InfoContext(ctx context.Context, message string, tag ...string) {
+	l.logCtx(ctx, LevelInfo, message, tag)
+}
+
+// ErrorContext logs an error message with the fields extracted from ctx
+func (l *Logger) ErrorContext(ctx context.Context, message string, tag ...string) {
+	l.logCtx(ctx, LevelError, message, tag)
+}
+
+// SuccessContext logs a success message with the fields extracted from ctx
+func (l *Logger) SuccessContext(ctx context.Context, message string, tag ...string) {
+	l.logCtx(ctx, LevelSuccess, message, tag)
+}
+
+// OngoingContext logs an ongoing operation message with the fields extracted from ctx
+func (l *Logger) OngoingContext(ctx context.Context, message string, tag ...string) {
+	l.logCtx(ctx, LevelOngoing, message, tag)
+}
+
+// WarningContext logs a warning message using the logger stored in ctx
+func WarningContext(ctx context.Context, message string, tag ...string) {
+	FromContext(ctx).logCtx(ctx, LevelWarning, message, tag)
+}
+
+// MessageContext logs a message using the logger stored in ctx
+func MessageContext(ctx context.Context, message string, tag ...string) {
+	FromContext(ctx).logCtx(ctx, LevelDebug, message, tag)
+}
+
+// InfoContext logs an info message using the logger stored in ctx
+func InfoContext(ctx context.Context, message string, tag ...string) {
+	FromContext(ctx).logCtx(ctx, LevelInfo, message, tag)
+}
+
+// ErrorContext logs an error message using the logger stored in ctx
+func ErrorContext(ctx context.Context, message string, tag ...string) {
+	FromContext(ctx).logCtx(ctx, LevelError, message, tag)
+}
+
+// SuccessContext logs a success message using the logger stored in ctx
+func SuccessContext(ctx context.Context, message string, tag ...string) {
+	FromContext(ctx).logCtx(ctx, LevelSuccess, message, tag)
+}
+
+// OngoingContext logs an ongoing operation message using the logger stored in ctx
+func OngoingContext(ctx context.Context, message string, tag ...string) {
+	FromContext(ctx).logCtx(ctx, LevelOngoing, message, tag)
+}
+
+// AddContextExtractor adds a context extractor to the default logger
+func AddContextExtractor(fn ContextExtractor) {
+	DefaultLogger.AddContextExtractor(fn)
+}
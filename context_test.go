@@ -0,0 +1,113 @@
+package ulog
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type requestIDKey struct{}
+
+func TestWithContextRoundTrip(t *testing.T) {
+	var out recorder
+	l := testLogger(&out).With("user", 42)
+	ctx := WithContext(context.Background(), l)
+	if got := FromContext(ctx); got != l {
+		t.Errorf("FromContext returned %p, want %p", got, l)
+	}
+
+	// A context without a logger, or with a nil one, falls back to the default logger
+	if got := FromContext(context.Background()); got != DefaultLogger {
+		t.Error("FromContext without a logger did not return DefaultLogger")
+	}
+	if got := FromContext(WithContext(context.Background(), nil)); got != DefaultLogger {
+		t.Error("FromContext with a nil logger did not return DefaultLogger")
+	}
+}
+
+func TestContextExtractorFields(t *testing.T) {
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-7")
+	extract := WithContextExtractors(ContextValue(requestIDKey{}, "request_id"))
+
+	t.Run("box", func(t *testing.T) {
+		t.Setenv("COLUMNS", "")
+		var out recorder
+		l := testLogger(&out, extract)
+		l.With("user", 42).InfoContext(ctx, "handled")
+		boxes := splitBoxes(t, out.String())
+		if len(boxes) != 1 {
+			t.Fatalf("got %d boxes, want 1:\n%s", len(boxes), out.String())
+		}
+		checkBox(t, boxes[0])
+		box := strings.Join(boxes[0], "\n")
+		if !strings.Contains(box, "request_id") || !strings.Contains(box, "req-7") ||
+			strings.Index(box, "user") > strings.Index(box, "request_id") {
+			t.Errorf("box does not end with the extracted field:\n%s", box)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var out recorder
+		l := testLogger(&out, extract, WithEncoder(JSONEncoder{}))
+		l.With("user", 42).InfoContext(ctx, "handled")
+		if s := out.String(); !strings.Contains(s, `"msg":"handled","user":42,"request_id":"req-7"}`) {
+			t.Errorf("output = %s", s)
+		}
+	})
+
+	t.Run("logfmt", func(t *testing.T) {
+		var out recorder
+		l := testLogger(&out, extract, WithEncoder(LogfmtEncoder{}))
+		ErrorContext(WithContext(ctx, l.With("user", 42)), "failed")
+		if s := out.String(); !strings.Contains(s, "level=error msg=failed user=42 request_id=req-7\n") {
+			t.Errorf("output = %s", s)
+		}
+	})
+
+	t.Run("missing value", func(t *testing.T) {
+		var out recorder
+		l := testLogger(&out, extract, WithEncoder(LogfmtEncoder{}))
+		l.InfoContext(context.Background(), "handled")
+		if s := out.String(); strings.Contains(s, "request_id") {
+			t.Errorf("output = %s", s)
+		}
+	})
+}
+
+func TestContextExtractorAddedToChild(t *testing.T) {
+	var out recorder
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-7")
+	parent := testLogger(&out, WithEncoder(LogfmtEncoder{}))
+	child := parent.Named("child")
+	child.AddContextExtractor(ContextValue(requestIDKey{}, "request_id"))
+
+	parent.InfoContext(ctx, "parent")
+	child.InfoContext(ctx, "child")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || strings.Contains(lines[0], "request_id") || !strings.Contains(lines[1], "request_id=req-7") {
+		t.Errorf("output = %q", lines)
+	}
+}
+
+func TestContextCaller(t *testing.T) {
+	var out recorder
+	l := testLogger(&out, WithEncoder(LogfmtEncoder{}), WithCaller(CallerFile))
+	ctx := WithContext(context.Background(), l)
+
+	_, _, line, _ := runtime.Caller(0)
+	l.InfoContext(ctx, "method")
+	InfoContext(ctx, "function")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), lines)
+	}
+	for i, l := range lines {
+		want := "caller=context_test.go:" + strconv.Itoa(line+1+i) + " "
+		if !strings.Contains(l, want) {
+			t.Errorf("line %q does not contain %q", l, want)
+		}
+	}
+}
@@ -9,8 +9,29 @@ formatting to improve readability and debugging experience.
 # Features
 
   - Colorful boxed messages with customizable tags
+  - Boxes stay aligned with emoji, CJK text, accents and pre-coloured (ANSI) content
+  - Word wrapping to fit the terminal width
+  - Pluggable box styles, including pure ASCII and borderless
+  - Themes with 16-color, 256-color and true-color support
+  - Automatic color detection honoring NO_COLOR, FORCE_COLOR and TTY state
+  - Pluggable encoders, including compact single-line, JSON Lines and logfmt output
+  - Rotating log files with size, age and backup-count limits
+  - Multiple sinks with per-sink encoder, level and tag filter
+  - Asynchronous logging with a bounded queue and Flush/Close
   - Multiple message types (Error, Warning, Info, Success, Ongoing, Message)
   - Timestamp support
+  - Severity levels with minimum-level filtering
+  - Printf-style variants with lazy formatting
+  - Fatal and Panic with exit hooks and a replaceable exit function
+  - Structured key/value fields
+  - Child loggers with nested tags and inherited settings
+  - Optional caller file:line and function annotation
+  - Hooks to enrich, drop or react to records
+  - Sampling of repeated messages with periodic summaries
+  - Collapsing of identical consecutive messages with a repeat count
+  - Redaction of secrets and personal data
+  - log/slog handler backed by the box renderer
+  - context.Context integration with request-scoped loggers
   - Structured data display utilities
   - Simple API with both global functions and configurable logger instances
 
@@ -49,6 +70,11 @@ To install ulog, use the following command:
 	    ulog.PrintMapWithIndent(data, "  ")
 	}
 
+Every message type has a formatted variant such as Infof or Errorf. The tag
+of formatted messages comes from the logger:
+
+	logger.WithTag("DB").Errorf("query failed after %d retries", n)
+
 # Creating Custom Loggers
 
 You can create custom logger instances with specific settings:
@@ -57,6 +83,148 @@ You can create custom logger instances with specific settings:
 	customLogger := ulog.NewLogger(false, 2)
 	customLogger.Success("This is a custom formatted message", "CUSTOM")
 
+Options can redirect the output to any io.Writer, with an optional separate
+writer for Error and Warning boxes:
+
+	logger := ulog.NewLogger(true, 1,
+	    ulog.WithOutput(logFile),
+	    ulog.WithErrorOutput(os.Stderr),
+	)
+
+Several sinks can receive the same message, each with its own encoder,
+minimum level and tag filter. Write errors of one sink do not affect the
+others and are reported to the error handler:
+
+	logger := ulog.NewLogger(true, 1, ulog.WithSinks(
+	    &ulog.Sink{Writer: os.Stdout},
+	    &ulog.Sink{Writer: logFile, Encoder: ulog.JSONEncoder{}, Level: ulog.LevelWarning},
+	))
+
+# Concurrency
+
+A Logger is safe for concurrent use. Each box is written in a single write
+under a lock of its sink, which is shared with child loggers, and SetTimestamp, SetPadding,
+SetLevel, SetOutput and SetErrorOutput may be called at any time.
+
+# Asynchronous Logging
+
+WithAsync moves writing to a background goroutine with a bounded queue. The
+overflow policy chooses between blocking, dropping the newest or dropping the
+oldest record when the queue is full. Flush and Close drain the queue:
+
+	logger := ulog.NewLogger(true, 1, ulog.WithAsync(ulog.AsyncOptions{Overflow: ulog.OverflowDropOldest}))
+	defer logger.Close()
+
+# Log Levels
+
+Each message type has a severity Level (Message is LevelDebug, Info is
+LevelInfo, and so on up to LevelFatal). Messages below a logger's level are
+dropped before they are formatted:
+
+	logger := ulog.NewLogger(true, 1, ulog.WithLevel(ulog.LevelWarning))
+	logger.Info("not printed")
+	logger.SetLevel(ulog.LevelDebug)
+
+Fatal logs at LevelFatal, flushes every sink, runs the hooks registered with
+RegisterExitHook and calls os.Exit(1), which WithExitFunc can replace in
+tests. Panic logs at LevelPanic and then panics with the message.
+
+# Structured Fields
+
+With returns a logger that attaches key/value fields to every message. The
+fields are shown as an aligned section inside the box:
+
+	reqLogger := logger.With("user", 42, "request_id", "a1b2")
+	reqLogger.Info("Request started", "API")
+
+# Caller Annotation
+
+WithCaller shows the file and line (CallerFile), and optionally the function
+(CallerFunction), that logged each message next to the timestamp. Structured
+encoders write it as "caller" and "func" keys:
+
+	logger := ulog.NewLogger(true, 1, ulog.WithCaller(ulog.CallerFile))
+
+# Redaction
+
+A Redactor masks bearer tokens, AWS keys, JWTs, emails and card numbers, the
+values of keys such as "password" or "authorization", and struct fields tagged
+`ulog:"redact"`. The default logger's redactor also applies to PrintMap and
+the other data-structure printers:
+
+	ulog.SetRedactor(ulog.NewRedactor())
+	ulog.Error("login failed for bob@example.com") // login failed for [REDACTED]
+
+# Collapsing Repeated Messages
+
+WithCollapse merges identical consecutive messages into one, shown with a
+counter such as "×37 (last at 15:04:09)". A message is written when a different
+one arrives, after the timeout, or on Flush:
+
+	logger := ulog.NewLogger(true, 1, ulog.WithCollapse(2*time.Second))
+
+# Sampling
+
+WithSampling gives each level, tag and message a token bucket of First records
+per interval, then logs only every Nth one, reporting the rest in a summary
+such as "suppressed 4,312 similar warnings":
+
+	logger := ulog.NewLogger(true, 1, ulog.WithSampling(ulog.SamplingOptions{First: 5, Thereafter: 1000}))
+
+# Hooks
+
+A Hook sees every record before sampling, collapsing and rendering, where it
+can modify or drop it, and after it has been written. Hooks run in the order
+they were added:
+
+	logger.AddHook(ulog.HookFuncs{BeforeFunc: func(r *ulog.Record) bool {
+	    if r.Level >= ulog.LevelError {
+	        errorsTotal.Inc()
+	    }
+	    return true
+	}})
+
+# Child Loggers
+
+Named returns a child logger whose tag nests under its parent's tag. Child
+loggers follow the parent's level, settings, hooks and context extractors
+unless they override or add to them:
+
+	db := logger.Named("api").Named("db")
+	db.Info("connected") // tagged "api.db"
+	db.SetLevel(ulog.LevelDebug)
+
+A logger and its children share one set of sinks, so SetOutput, SetErrorOutput,
+AddSink, SetSinks, SetErrorHandler, SetAsync, SetSampling, SetCollapse and
+SetExitFunc change the whole logger tree, whichever logger they are called on.
+
+# Context Integration
+
+WithContext stores a logger in a context and FromContext returns it. The
+Context variants of the logging functions add the fields returned by the
+context extractors:
+
+	logger.AddContextExtractor(ulog.ContextValue(requestIDKey{}, "request_id"))
+	ctx = ulog.WithContext(ctx, logger.Named("api"))
+	ulog.InfoContext(ctx, "request started")
+
+# log/slog Integration
+
+NewSlogHandler adapts a Logger to slog.Handler, rendering attributes and groups
+as fields inside the box:
+
+	slog.SetDefault(slog.New(ulog.NewSlogHandler(logger, nil)))
+	slog.Warn("Disk almost full", "free", "2GB")
+
+# Encoders
+
+A Logger renders records with an Encoder. BoxEncoder is the default,
+CompactEncoder prints one colored line per message, JSONEncoder writes one
+JSON object per line and LogfmtEncoder writes key=value lines. Setting ULOG_FORMAT=json (or logfmt) switches new loggers
+without code changes:
+
+	logger := ulog.NewLogger(true, 1, ulog.WithEncoder(ulog.JSONEncoder{}))
+
 # Data Structure Utilities
 
 The package also provides utilities for working with data structures:
@@ -0,0 +1,205 @@
+package ulog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// EnvFormat is the environment variable that selects the encoder of new loggers,
+// e.g. ULOG_FORMAT=json. An encoder passed with WithEncoder takes precedence.
+const EnvFormat = "ULOG_FORMAT"
+
+// Encoder turns a record into the bytes written to the output.
+// Implementations append one complete entry, including its trailing newline, to buf.
+type Encoder interface {
+	Encode(buf *bytes.Buffer, r *Record) error
+}
+
+// renderHints carries the logger settings and properties of the output writer
+// that console encoders such as BoxEncoder need to render a record
+type renderHints struct {
+	st      settings
+	w       io.Writer
+	profile ColorProfile
+}
+
+// hintsFor returns the render hints of the record, or defaults without colors
+// when the record is encoded outside of a Logger
+func hintsFor(r *Record) *renderHints {
+	if r.hints != nil {
+		return r.hints
+	}
+	return &renderHints{st: defaultSettings(), profile: ProfileNoColor}
+}
+
+// BoxEncoder renders records as colored boxes using the logger's box style, theme,
+// padding and timestamp settings. It is the default encoder.
+type BoxEncoder struct{}
+
+// Encode implements Encoder
+func (BoxEncoder) Encode(buf *bytes.Buffer, r *Record) error {
+	h := hintsFor(r)
+	buf.WriteString(h.st.formatBox(r, availableWidth(h.w, h.st.maxWidth), h.profile))
+	buf.WriteByte('\n')
+	return nil
+}
+
+// JSONEncoder renders each record as a single-line JSON object (JSON Lines) with
+// "time", "level", "tag", "caller", "func" and "msg" keys followed by the record's fields.
+// Collapsed messages also have "repeat" and "last" keys, and records without a time have no "time" key.
+// Fields whose key clashes with one of these are prefixed with "fields.".
+type JSONEncoder struct {
+	// TimeFormat is the layout of the "time" value. Defaults to time.RFC3339Nano.
+	TimeFormat string
+}
+
+// Encode implements Encoder
+func (e JSONEncoder) Encode(buf *bytes.Buffer, r *Record) error {
+	layout := e.TimeFormat
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+
+	buf.WriteByte('{')
+	if !r.Time.IsZero() {
+		buf.WriteString(`"time":`)
+		writeJSON(buf, r.Time.Format(layout))
+		buf.WriteByte(',')
+	}
+	buf.WriteString(`"level":`)
+	writeJSON(buf, r.Level.String())
+	if r.Tag != "" {
+		buf.WriteString(`,"tag":`)
+		writeJSON(buf, r.Tag)
+	}
+	if r.Caller != nil {
+		buf.WriteString(`,"caller":`)
+		writeJSON(buf, r.Caller.Location())
+		if r.Caller.Function != "" {
+			buf.WriteString(`,"func":`)
+			writeJSON(buf, r.Caller.Function)
+		}
+	}
+	buf.WriteString(`,"msg":`)
+	writeJSON(buf, r.Message)
+	if r.Repeat > 1 {
+		buf.WriteString(`,"repeat":`)
+		writeJSON(buf, r.Repeat)
+		if !r.LastTime.IsZero() {
+			buf.WriteString(`,"last":`)
+			writeJSON(buf, r.LastTime.Format(layout))
+		}
+	}
+	for _, f := range r.Fields {
+		key := f.Key
+		switch key {
+		case "time", "level", "tag", "caller", "func", "msg", "repeat", "last":
+			key = "fields." + key
+		}
+		buf.WriteByte(',')
+		writeJSON(buf, key)
+		buf.WriteByte(':')
+		writeJSON(buf, jsonValue(f.Value))
+	}
+	buf.WriteString("}\n")
+	return nil
+}
+
+// jsonValue prepares a field value for JSON encoding: errors are written as their
+// message and ordered fields as objects
+func jsonValue(v any) any {
+	switch val := v.(type) {
+	case error:
+		return val.Error()
+	case []Field:
+		return orderedFields(val)
+	case map[string]any:
+		m := make(map[string]any, len(val))
+		for k, nested := range val {
+			m[k] = jsonValue(nested)
+		}
+		return m
+	case Fields:
+		return jsonValue(map[string]any(val))
+	}
+	return v
+}
+
+// orderedFields marshals fields as a JSON object keeping their order
+type orderedFields []Field
+
+// MarshalJSON implements json.Marshaler
+func (fields orderedFields) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeJSON(&buf, f.Key)
+		buf.WriteByte(':')
+		writeJSON(&buf, jsonValue(f.Value))
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// writeJSON appends the JSON encoding of v to buf without HTML escaping.
+// Values that cannot be encoded are written as their fmt representation.
+func writeJSON(buf *bytes.Buffer, v any) {
+	var tmp bytes.Buffer
+	enc := json.NewEncoder(&tmp)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		tmp.Reset()
+		enc.Encode(fmt.Sprintf("%+v", v))
+	}
+	buf.Write(bytes.TrimRight(tmp.Bytes(), "\n"))
+}
+
+// ParseEncoder returns the built-in encoder with the given name: "box", "compact", "json" or "logfmt"
+func ParseEncoder(name string) (Encoder, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "box", "":
+		return BoxEncoder{}, nil
+	case "compact":
+		return CompactEncoder{}, nil
+	case "json", "jsonl":
+		return JSONEncoder{}, nil
+	case "logfmt":
+		return LogfmtEncoder{}, nil
+	}
+	return nil, fmt.Errorf("ulog: unknown encoder %q", name)
+}
+
+// encoderFromEnv returns the encoder selected by the ULOG_FORMAT environment
+// variable, or the box encoder when it is unset or invalid
+func encoderFromEnv() Encoder {
+	enc, err := ParseEncoder(os.Getenv(EnvFormat))
+	if err != nil {
+		return BoxEncoder{}
+	}
+	return enc
+}
+
+// SetEncoder sets the encoder used to render records, e.g. JSONEncoder{}.
+// Passing nil restores the default box encoder.
+func (l *Logger) SetEncoder(enc Encoder) {
+	if enc == nil {
+		enc = BoxEncoder{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.s.encoder = enc
+	l.set |= setEncoder
+}
+
+// SetEncoder sets the encoder of the default logger
+func SetEncoder(enc Encoder) {
+	DefaultLogger.SetEncoder(enc)
+}
@@ -0,0 +1,141 @@
+package ulog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// fatalFlushTimeout bounds how long Fatal and Panic wait for queued records
+const fatalFlushTimeout = 5 * time.Second
+
+var (
+	exitHooksMu sync.Mutex
+	exitHooks   []func()
+)
+
+// RegisterExitHook registers a function that Fatal runs before exiting, e.g. to
+// close files or report to a monitoring service. Hooks run in registration order;
+// a panicking hook is reported to the error handler and does not stop the others.
+func RegisterExitHook(hook func()) {
+	if hook == nil {
+		return
+	}
+	exitHooksMu.Lock()
+	defer exitHooksMu.Unlock()
+	exitHooks = append(exitHooks, hook)
+}
+
+// runExitHooks runs the registered exit hooks, recovering from their panics
+func (c *core) runExitHooks() {
+	exitHooksMu.Lock()
+	hooks := append([]func(){}, exitHooks...)
+	exitHooksMu.Unlock()
+	for _, hook := range hooks {
+		func() {
+			defer func() {
+				if v := recover(); v != nil {
+					c.handleError(fmt.Errorf("ulog: exit hook panicked: %v", v))
+				}
+			}()
+			hook()
+		}()
+	}
+}
+
+// syncer is implemented by writers that buffer data, such as *os.File and *RotatingFile
+type syncer interface {
+	Sync() error
+}
+
+// sync writes the queued records and commits the sinks' writers to stable storage.
+// Sync errors are ignored, since terminals and pipes do not support it.
+func (c *core) sync() {
+	c.flushHeld()
+	c.mu.RLock()
+	q := c.async
+	c.mu.RUnlock()
+	if q != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), fatalFlushTimeout)
+		q.flush(ctx)
+		cancel()
+	}
+	for _, s := range c.snapshot() {
+		s.mu.Lock()
+		writers := []any{s.Writer, s.ErrorWriter}
+		s.mu.Unlock()
+		for _, w := range writers {
+			if w, ok := w.(syncer); ok {
+				w.Sync()
+			}
+		}
+	}
+}
+
+// exitFunc returns the function Fatal calls to end the program
+func (c *core) exitFunc() func(int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.exit != nil {
+		return c.exit
+	}
+	return os.Exit
+}
+
+// SetExitFunc replaces os.Exit as the function Fatal calls after logging,
+// e.g. to intercept the exit in tests. Passing nil restores os.Exit. The
+// function is shared by the whole logger tree.
+func (l *Logger) SetExitFunc(exit func(code int)) {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	l.core.exit = exit
+}
+
+// Fatal logs a message at LevelFatal, flushes all sinks, runs the exit hooks and
+// exits the program with status 1
+func (l *Logger) Fatal(message string, tag ...string) {
+	l.log(LevelFatal, message, tag)
+	l.exit()
+}
+
+// Fatalf formats a message like fmt.Sprintf and logs it with Fatal
+func (l *Logger) Fatalf(format string, args ...any) {
+	l.log(LevelFatal, fmt.Sprintf(format, args...), nil)
+	l.exit()
+}
+
+// Panic logs a message at LevelPanic, flushes all sinks and panics with the message
+func (l *Logger) Panic(message string, tag ...string) {
+	l.log(LevelPanic, message, tag)
+	l.core.sync()
+	panic(message)
+}
+
+// exit flushes the sinks, runs the exit hooks and ends the program after a fatal message
+func (l *Logger) exit() {
+	l.core.sync()
+	l.core.runExitHooks()
+	l.core.sync()
+	l.core.exitFunc()(1)
+}
+
+// Fatal logs a message using the default logger and exits the program
+func Fatal(message string, tag ...string) {
+	DefaultLogger.log(LevelFatal, message, tag)
+	DefaultLogger.exit()
+}
+
+// Fatalf logs a formatted message using the default logger and exits the program
+func Fatalf(format string, args ...any) {
+	DefaultLogger.log(LevelFatal, fmt.Sprintf(format, args...), nil)
+	DefaultLogger.exit()
+}
+
+// Panic logs a message using the default logger and panics
+func Panic(message string, tag ...string) {
+	DefaultLogger.log(LevelPanic, message, tag)
+	DefaultLogger.core.sync()
+	panic(message)
+}
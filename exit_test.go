@@ -0,0 +1,113 @@
+package ulog
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// resetExitHooks removes the registered exit hooks for the rest of the test
+func resetExitHooks(t *testing.T) {
+	exitHooksMu.Lock()
+	saved := exitHooks
+	exitHooks = nil
+	exitHooksMu.Unlock()
+	t.Cleanup(func() {
+		exitHooksMu.Lock()
+		exitHooks = saved
+		exitHooksMu.Unlock()
+	})
+}
+
+// slowWriter records writes after a delay, so that queued records are still
+// pending when the logging call returns
+type slowWriter struct {
+	recorder
+	delay time.Duration
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return w.recorder.Write(p)
+}
+
+func TestFatalRunsExitHooks(t *testing.T) {
+	for _, fatal := range []struct {
+		name string
+		call func(l *Logger)
+	}{
+		{"Fatal", func(l *Logger) { l.Fatal("shutting down") }},
+		{"Fatalf", func(l *Logger) { l.Fatalf("shutting %s", "down") }},
+	} {
+		t.Run(fatal.name, func(t *testing.T) {
+			resetExitHooks(t)
+			var out recorder
+			var calls []string
+			var errs []error
+			l := testLogger(&out, WithEncoder(LogfmtEncoder{}), WithErrorHandler(func(err error) { errs = append(errs, err) }))
+			l.SetExitFunc(func(code int) { calls = append(calls, "exit "+strconv.Itoa(code)) })
+
+			RegisterExitHook(func() {
+				if !strings.Contains(out.String(), `msg="shutting down"`) {
+					t.Error("exit hook ran before the fatal message was written")
+				}
+				calls = append(calls, "first")
+			})
+			RegisterExitHook(func() { panic("broken hook") })
+			RegisterExitHook(func() { calls = append(calls, "third") })
+			fatal.call(l)
+
+			if got := strings.Join(calls, ","); got != "first,third,exit 1" {
+				t.Errorf("calls = %s, want first,third,exit 1", got)
+			}
+			if len(errs) != 1 || !strings.Contains(errs[0].Error(), "broken hook") {
+				t.Errorf("errors = %v, want the hook panic", errs)
+			}
+			if s := out.String(); !strings.Contains(s, "level=fatal") {
+				t.Errorf("output = %q", s)
+			}
+		})
+	}
+}
+
+func TestFatalFlushesAsync(t *testing.T) {
+	resetExitHooks(t)
+	out := &slowWriter{delay: time.Millisecond}
+	l := testLogger(out, WithEncoder(LogfmtEncoder{}), WithAsync(AsyncOptions{BufferSize: 64}))
+	defer l.Close()
+
+	written := -1
+	l.SetExitFunc(func(code int) { written = strings.Count(out.String(), "\n") })
+	for i := 0; i < 20; i++ {
+		l.Info("queued")
+	}
+	l.Fatal("done")
+	if written != 21 {
+		t.Errorf("%d records written before exiting, want 21", written)
+	}
+}
+
+func TestPanic(t *testing.T) {
+	resetExitHooks(t)
+	var out recorder
+	l := testLogger(&out, WithEncoder(LogfmtEncoder{}))
+	exited := false
+	l.SetExitFunc(func(int) { exited = true })
+	RegisterExitHook(func() { t.Error("Panic ran an exit hook") })
+
+	defer func() {
+		v := recover()
+		if v != "state corrupted" {
+			t.Errorf("recovered %v, want the message", v)
+		}
+		if exited {
+			t.Error("Panic called the exit func")
+		}
+		if s := out.String(); !strings.Contains(s, `level=panic msg="state corrupted"`) {
+			t.Errorf("output = %q", s)
+		}
+	}()
+	l.Panic("state corrupted")
+	t.Error("Panic returned")
+}
@@ -0,0 +1,137 @@
+package ulog
+
+import (
+	"fmt"
+	"sort"
+)
+
+// badKey is used for values passed to With without a matching string key
+const badKey = "!BADKEY"
+
+// Field is a single structured key/value pair attached to a log message
+type Field struct {
+	Key   string
+	Value any
+}
+
+// F creates a Field from a key and a value
+func F(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Fields is a map of structured key/value pairs.
+// When attached to a logger the keys are added in sorted order.
+type Fields map[string]any
+
+// With returns a child logger that adds the given key/value pairs to every message.
+// Arguments are alternating keys and values, e.g. l.With("user", id, "req", rid).
+// A Field or Fields argument is added as-is, and a value without a string key is
+// recorded under the "!BADKEY" key.
+//
+// Example:
+//
+//	reqLogger := logger.With("user", 42, "req", "a1b2")
+//	reqLogger.Info("Request started")
+func (l *Logger) With(args ...any) *Logger {
+	if len(args) == 0 {
+		return l
+	}
+	child := l.clone()
+	child.fields = appendFields(child.fields, argsToFields(args)...)
+	return child
+}
+
+// WithFields returns a child logger that adds the given fields to every message
+func (l *Logger) WithFields(fields Fields) *Logger {
+	return l.With(fields)
+}
+
+// appendFields appends to a copy of dst so that loggers never share a backing array
+func appendFields(dst []Field, fields ...Field) []Field {
+	result := make([]Field, 0, len(dst)+len(fields))
+	result = append(result, dst...)
+	return append(result, fields...)
+}
+
+// argsToFields converts alternating key/value arguments into fields
+func argsToFields(args []any) []Field {
+	fields := make([]Field, 0, len(args)/2+1)
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i].(type) {
+		case Field:
+			fields = append(fields, arg)
+		case []Field:
+			fields = append(fields, arg...)
+		case Fields:
+			fields = append(fields, arg.sorted()...)
+		case string:
+			if i+1 < len(args) {
+				fields = append(fields, Field{Key: arg, Value: args[i+1]})
+				i++
+			} else {
+				fields = append(fields, Field{Key: badKey, Value: arg})
+			}
+		default:
+			fields = append(fields, Field{Key: badKey, Value: arg})
+		}
+	}
+	return fields
+}
+
+// sorted returns the map entries as fields ordered by key
+func (f Fields) sorted() []Field {
+	keys := make([]string, 0, len(f))
+	for k := range f {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fields := make([]Field, 0, len(keys))
+	for _, k := range keys {
+		fields = append(fields, Field{Key: k, Value: f[k]})
+	}
+	return fields
+}
+
+// flattenFields expands nested maps into dotted keys, e.g. {"db": {"host": "x"}}
+// becomes "db.host". Nested keys are sorted for consistent output.
+func flattenFields(fields []Field) []Field {
+	flat := make([]Field, 0, len(fields))
+	for _, f := range fields {
+		flat = flattenValue(flat, f.Key, f.Value)
+	}
+	return flat
+}
+
+func flattenValue(dst []Field, key string, value any) []Field {
+	var nested []Field
+	switch v := value.(type) {
+	case map[string]any:
+		nested = Fields(v).sorted()
+	case Fields:
+		nested = v.sorted()
+	case []Field:
+		nested = v
+	default:
+		return append(dst, Field{Key: key, Value: value})
+	}
+	if len(nested) == 0 {
+		return append(dst, Field{Key: key, Value: value})
+	}
+	for _, f := range nested {
+		dst = flattenValue(dst, key+"."+f.Key, f.Value)
+	}
+	return dst
+}
+
+// fieldValueString formats a field value for display inside a box
+func fieldValueString(v any) string {
+	switch val := v.(type) {
+	case error:
+		return valueAsString(val.Error())
+	case fmt.Stringer:
+		return valueAsString(val.String())
+	default:
+		return valueAsString(val)
+	}
+}
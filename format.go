@@ -0,0 +1,87 @@
+package ulog
+
+// SetTag sets the tag shown on messages logged without a tag of their own,
+// including the formatted variants such as Infof
+func (l *Logger) SetTag(tag string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.s.tag = tag
+	l.set |= setTag
+}
+
+// WithTag returns a child logger that tags messages logged without a tag
+// of their own. It is the way to tag the formatted variants:
+//
+//	logger.WithTag("DB").Errorf("query failed after %d retries", n)
+func (l *Logger) WithTag(tag string) *Logger {
+	child := l.clone()
+	child.s.tag = tag
+	child.set |= setTag
+	return child
+}
+
+// Warningf formats a message like fmt.Sprintf and logs it with Warning.
+// Nothing is formatted when LevelWarning is disabled.
+func (l *Logger) Warningf(format string, args ...any) {
+	l.logf(LevelWarning, format, args)
+}
+
+// Messagef formats a message like fmt.Sprintf and logs it with Message
+func (l *Logger) Messagef(format string, args ...any) {
+	l.logf(LevelDebug, format, args)
+}
+
+// Infof formats a message like fmt.Sprintf and logs it with Info
+func (l *Logger) Infof(format string, args ...any) {
+	l.logf(LevelInfo, format, args)
+}
+
+// Errorf formats a message like fmt.Sprintf and logs it with Error
+func (l *Logger) Errorf(format string, args ...any) {
+	l.logf(LevelError, format, args)
+}
+
+// Successf formats a message like fmt.Sprintf and logs it with Success
+func (l *Logger) Successf(format string, args ...any) {
+	l.logf(LevelSuccess, format, args)
+}
+
+// Ongoingf formats a message like fmt.Sprintf and logs it with Ongoing
+func (l *Logger) Ongoingf(format string, args ...any) {
+	l.logf(LevelOngoing, format, args)
+}
+
+// Warningf logs a formatted warning message using the default logger
+func Warningf(format string, args ...any) {
+	DefaultLogger.logf(LevelWarning, format, args)
+}
+
+// Messagef logs a formatted message using the default logger
+func Messagef(format string, args ...any) {
+	DefaultLogger.logf(LevelDebug, format, args)
+}
+
+// Infof logs a formatted info message using the default logger
+func Infof(format string, args ...any) {
+	DefaultLogger.logf(LevelInfo, format, args)
+}
+
+// Errorf logs a formatted error message using the default logger
+func Errorf(format string, args ...any) {
+	DefaultLogger.logf(LevelError, format, args)
+}
+
+// Successf logs a formatted success message using the default logger
+func Successf(format string, args ...any) {
+	DefaultLogger.logf(LevelSuccess, format, args)
+}
+
+// Ongoingf logs a formatted ongoing operation message using the default logger
+func Ongoingf(format string, args ...any) {
+	DefaultLogger.logf(LevelOngoing, format, args)
+}
+
+// SetTag sets the tag of untagged messages logged with the default logger
+func SetTag(tag string) {
+	DefaultLogger.SetTag(tag)
+}
@@ -0,0 +1,68 @@
+package ulog
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// countingStringer counts how often it is formatted
+type countingStringer struct {
+	calls atomic.Int32
+}
+
+func (s *countingStringer) String() string {
+	s.calls.Add(1)
+	return "expensive"
+}
+
+func TestFormatSkippedWhenDisabled(t *testing.T) {
+	var out recorder
+	var arg countingStringer
+	l := testLogger(&out, WithEncoder(LogfmtEncoder{}))
+	l.SetLevel(LevelError)
+
+	l.Messagef("value %s", &arg)
+	l.Infof("value %s", &arg)
+	l.Warningf("value %s", &arg)
+	l.Successf("value %s", &arg)
+	if n := arg.calls.Load(); n != 0 {
+		t.Errorf("arguments formatted %d times for disabled levels", n)
+	}
+	if s := out.String(); s != "" {
+		t.Errorf("output = %q", s)
+	}
+
+	l.Errorf("value %s", &arg)
+	if n := arg.calls.Load(); n != 1 {
+		t.Errorf("arguments formatted %d times, want 1", n)
+	}
+	if s := out.String(); !strings.Contains(s, `level=error msg="value expensive"`) {
+		t.Errorf("output = %q", s)
+	}
+}
+
+func TestFormatTags(t *testing.T) {
+	var out recorder
+	l := testLogger(&out, WithEncoder(LogfmtEncoder{}), WithDefaultTag("APP"))
+	l.Infof("started %d workers", 4)
+	l.WithTag("DB").Errorf("query failed after %d retries", 3)
+	l.WithTag("DB").Warning("slow", "CACHE")
+	l.Named("api").Successf("listening on %s", ":8080")
+
+	want := []string{
+		`level=info tag=APP msg="started 4 workers"`,
+		`level=error tag=DB msg="query failed after 3 retries"`,
+		`level=warning tag=CACHE msg=slow`,
+		`level=success tag=APP.api msg="listening on :8080"`,
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d: %q", len(lines), len(want), lines)
+	}
+	for i := range want {
+		if !strings.HasSuffix(lines[i], want[i]) {
+			t.Errorf("line %d = %q, want it to end with %q", i, lines[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package ulog
+
+import "fmt"
+
+// Hook intercepts the records of a Logger. Hooks run in the order they were
+// added, for records that pass the logger's level.
+//
+// Before runs for every logged record, ahead of sampling and collapsing, so it
+// sees each call even when the record is later suppressed. It may modify the
+// record, e.g. to add fields or change the tag, and returns false to drop it.
+// Fields shared with the logger must not be modified in place; assign a new slice
+// to r.Fields instead (appending is safe). After runs once a record has been
+// written to the sinks and must not modify it; it sees collapsed records and
+// sampling summaries, but not the records they replace.
+//
+// A hook that panics is reported to the error handler and skipped; it does not
+// stop the record or the other hooks.
+type Hook interface {
+	Before(r *Record) bool
+	After(r *Record)
+}
+
+// HookFuncs adapts functions to a Hook. Either function may be nil.
+//
+//	logger.AddHook(ulog.HookFuncs{
+//	    BeforeFunc: func(r *ulog.Record) bool {
+//	        if r.Level >= ulog.LevelError {
+//	            errorCount.Inc()
+//	        }
+//	        return true
+//	    },
+//	    AfterFunc: func(r *ulog.Record) {
+//	        if r.Level == ulog.LevelSuccess {
+//	            notify(r.Message)
+//	        }
+//	    },
+//	})
+type HookFuncs struct {
+	BeforeFunc func(r *Record) bool
+	AfterFunc  func(r *Record)
+}
+
+// Before implements Hook
+func (h HookFuncs) Before(r *Record) bool {
+	if h.BeforeFunc == nil {
+		return true
+	}
+	return h.BeforeFunc(r)
+}
+
+// After implements Hook
+func (h HookFuncs) After(r *Record) {
+	if h.AfterFunc != nil {
+		h.AfterFunc(r)
+	}
+}
+
+// runBefore runs the Before hooks and reports whether the record should be written
+func (c *core) runBefore(r *Record, hooks []Hook) bool {
+	if len(hooks) == 0 {
+		return true
+	}
+	// Appending to a clipped slice never writes into the logger's own fields
+	r.Fields = r.Fields[:len(r.Fields):len(r.Fields)]
+	for _, h := range hooks {
+		if !c.callBefore(h, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// callBefore runs one Before hook, keeping the record when it panics
+func (c *core) callBefore(h Hook, r *Record) (keep bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			c.handleError(fmt.Errorf("ulog: hook %T panicked: %v", h, v))
+			keep = true
+		}
+	}()
+	return h.Before(r)
+}
+
+// runAfter runs the After hooks
+func (c *core) runAfter(r *Record, hooks []Hook) {
+	for _, h := range hooks {
+		func() {
+			defer func() {
+				if v := recover(); v != nil {
+					c.handleError(fmt.Errorf("ulog: hook %T panicked: %v", h, v))
+				}
+			}()
+			h.After(r)
+		}()
+	}
+}
+
+// AddHook adds a hook to the logger and its children, including existing ones.
+// Hooks run in the order they were added, those of a child's parents first; a
+// hook added to a child does not run for its parent or siblings.
+func (l *Logger) AddHook(h Hook) {
+	if h == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.s.hooks = append(l.s.hooks[:len(l.s.hooks):len(l.s.hooks)], h)
+}
+
+// AddHook adds a hook to the default logger
+func AddHook(h Hook) {
+	DefaultLogger.AddHook(h)
+}
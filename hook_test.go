@@ -0,0 +1,77 @@
+package ulog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHookBeforeSeesSampledRecords(t *testing.T) {
+	var out recorder
+	var before, after int
+	l := testLogger(&out, WithEncoder(LogfmtEncoder{}),
+		WithSampling(SamplingOptions{Interval: time.Hour, First: 2}),
+		WithHooks(HookFuncs{
+			BeforeFunc: func(r *Record) bool { before++; return true },
+			AfterFunc:  func(r *Record) { after++ },
+		}))
+	for i := 0; i < 10; i++ {
+		l.Error("boom")
+	}
+	if before != 10 {
+		t.Errorf("Before ran %d times, want 10", before)
+	}
+	if after != 2 {
+		t.Errorf("After ran %d times, want 2", after)
+	}
+}
+
+func TestHookOrderDropAndPanic(t *testing.T) {
+	var out recorder
+	var calls []string
+	var errs []error
+	l := testLogger(&out, WithEncoder(LogfmtEncoder{}),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }),
+		WithHooks(
+			HookFuncs{BeforeFunc: func(r *Record) bool { calls = append(calls, "first"); panic("broken hook") }},
+			HookFuncs{BeforeFunc: func(r *Record) bool {
+				calls = append(calls, "second")
+				r.Fields = append(r.Fields, F("version", "1.2.3"))
+				return r.Tag != "NOISY"
+			}},
+		))
+	l.Info("kept")
+	l.Info("dropped", "NOISY")
+
+	if got := strings.Join(calls, ","); got != "first,second,first,second" {
+		t.Errorf("hooks ran as %s", got)
+	}
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), "broken hook") {
+		t.Errorf("errors = %v, want the two panics", errs)
+	}
+	if s := out.String(); !strings.Contains(s, "msg=kept version=1.2.3") || strings.Contains(s, "dropped") {
+		t.Errorf("output = %q", s)
+	}
+}
+
+func TestHookAddedToChild(t *testing.T) {
+	var out recorder
+	var calls []string
+	hook := func(name string) Hook {
+		return HookFuncs{BeforeFunc: func(r *Record) bool {
+			calls = append(calls, name+":"+r.Message)
+			return true
+		}}
+	}
+	parent := testLogger(&out, WithEncoder(LogfmtEncoder{}), WithHooks(hook("parent")))
+	child := parent.Named("child")
+	sibling := parent.Named("sibling")
+	child.AddHook(hook("child"))
+
+	parent.Info("p")
+	child.Info("c")
+	sibling.Info("s")
+	if got := strings.Join(calls, ","); got != "parent:p,parent:c,child:c,parent:s" {
+		t.Errorf("hooks ran as %s", got)
+	}
+}
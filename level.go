@@ -0,0 +1,80 @@
+package ulog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is the severity of a log message.
+// Levels are ordered, so a Logger set to LevelWarning drops everything below it.
+type Level int
+
+// Log levels from least to most severe
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelSuccess
+	LevelOngoing
+	LevelWarning
+	LevelError
+	LevelPanic
+	LevelFatal
+)
+
+var levelNames = map[Level]string{
+	LevelDebug:   "debug",
+	LevelInfo:    "info",
+	LevelSuccess: "success",
+	LevelOngoing: "ongoing",
+	LevelWarning: "warning",
+	LevelError:   "error",
+	LevelPanic:   "panic",
+	LevelFatal:   "fatal",
+}
+
+// String returns the lower-case name of the level, e.g. "warning"
+func (lv Level) String() string {
+	if name, ok := levelNames[lv]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int(lv))
+}
+
+// ParseLevel converts a level name such as "info" or "WARN" to a Level.
+// Matching is case-insensitive and accepts the common "warn" and "err" aliases.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "success":
+		return LevelSuccess, nil
+	case "ongoing":
+		return LevelOngoing, nil
+	case "warning", "warn":
+		return LevelWarning, nil
+	case "error", "err":
+		return LevelError, nil
+	case "panic":
+		return LevelPanic, nil
+	case "fatal":
+		return LevelFatal, nil
+	}
+	return LevelDebug, fmt.Errorf("ulog: unknown level %q", name)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (lv Level) MarshalText() ([]byte, error) {
+	return []byte(lv.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (lv *Level) UnmarshalText(text []byte) error {
+	parsed, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*lv = parsed
+	return nil
+}
@@ -17,13 +17,19 @@ import (
 //
 //	data := map[string]any{"key1": "value1", "key2": map[string]any{"nested": "value"}}
 //	PrintMap(data)
+//
+// Sensitive values are masked when the default logger has a Redactor (see SetRedactor).
 func PrintMap(m map[string]any) {
+	printMap(printRedact(m).(map[string]any))
+}
+
+func printMap(m map[string]any) {
 	for key, value := range m {
 
 		switch v := value.(type) {
 		case map[string]any:
 			fmt.Printf("%s: {\n", key)
-			PrintMap(v) // Recursive call for nested maps
+			printMap(v) // Recursive call for nested maps
 			fmt.Println("}")
 		default:
 			fmt.Printf("%s: %v\n", key, value)
@@ -46,7 +52,7 @@ func PrintMap(m map[string]any) {
 //	// 2: banana
 //	// 3: cherry
 func PrintList(list []string) {
-	for i, item := range list {
+	for i, item := range printRedact(list).([]string) {
 		fmt.Printf("%d: %s\n", i+1, item)
 	}
 }
@@ -67,7 +73,13 @@ func PrintList(list []string) {
 //	data := map[string]interface{}{"name": "John", "age": 30}
 //	str := MapAsPrettyString(data, "User info:")
 //	// str = "User info: {age: 30, name: "John"}"
+//
+// Sensitive values are masked when the default logger has a Redactor (see SetRedactor).
 func MapAsPrettyString(m map[string]interface{}, beforeMessage ...string) string {
+	return mapAsPrettyString(printRedact(m).(map[string]any), beforeMessage...)
+}
+
+func mapAsPrettyString(m map[string]interface{}, beforeMessage ...string) string {
 	result := "{"
 	first := true
 	for k, v := range m {
@@ -75,7 +87,7 @@ func MapAsPrettyString(m map[string]interface{}, beforeMessage ...string) string
 			result += ", "
 		}
 		first = false
-		result += k + ": " + ValueAsString(v)
+		result += k + ": " + valueAsString(v)
 	}
 	result += "}"
 
@@ -101,13 +113,17 @@ func MapAsPrettyString(m map[string]interface{}, beforeMessage ...string) string
 //	str := ValueAsString(42)       // Returns: 42
 //	str := ValueAsString(map[string]interface{}{"key": "value"})  // Returns: {key: "value"}
 func ValueAsString(v interface{}) string {
+	return valueAsString(printRedact(v))
+}
+
+func valueAsString(v interface{}) string {
 	switch val := v.(type) {
 	case string:
 		return `"` + val + `"`
 	case int, int64, float64, float32, bool:
 		return fmt.Sprintf("%v", val)
 	case map[string]interface{}:
-		return MapAsPrettyString(val)
+		return mapAsPrettyString(val)
 	default:
 		return fmt.Sprintf("%v", val)
 	}
@@ -132,7 +148,7 @@ func ValueAsString(v interface{}) string {
 func ListAsPrettyString(list []string, beforeMessage ...string) string {
 	result := "["
 	first := true
-	for _, v := range list {
+	for _, v := range printRedact(list).([]string) {
 		if !first {
 			result += ", "
 		}
@@ -166,7 +182,7 @@ func ListAsPrettyString(list []string, beforeMessage ...string) string {
 func ListAsPrettyStringWithIndex(list []string, beforeMessage ...string) string {
 	result := "["
 	first := true
-	for i, v := range list {
+	for i, v := range printRedact(list).([]string) {
 		if !first {
 			result += ", "
 		}
@@ -198,6 +214,10 @@ func ListAsPrettyStringWithIndex(list []string, beforeMessage ...string) string
 //	}
 //	PrintMapWithIndent(data, "")
 func PrintMapWithIndent(m map[string]interface{}, indent string) {
+	printMapWithIndent(printRedact(m).(map[string]any), indent)
+}
+
+func printMapWithIndent(m map[string]interface{}, indent string) {
 	// Get all keys and sort them for consistent output
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -210,14 +230,14 @@ func PrintMapWithIndent(m map[string]interface{}, indent string) {
 		switch value := v.(type) {
 		case map[string]interface{}:
 			fmt.Printf("%s%s: {\n", indent, k)
-			PrintMapWithIndent(value, indent+"  ")
+			printMapWithIndent(value, indent+"  ")
 			fmt.Printf("%s}\n", indent)
 		case []interface{}:
 			fmt.Printf("%s%s: [\n", indent, k)
 			for i, item := range value {
 				if nestedMap, ok := item.(map[string]interface{}); ok {
 					fmt.Printf("%s  [%d]: {\n", indent, i)
-					PrintMapWithIndent(nestedMap, indent+"    ")
+					printMapWithIndent(nestedMap, indent+"    ")
 					fmt.Printf("%s  }\n", indent)
 				} else {
 					fmt.Printf("%s  [%d]: %v\n", indent, i, item)
@@ -232,8 +252,9 @@ func PrintMapWithIndent(m map[string]interface{}, indent string) {
 
 // Print struct prints the contents of a struct to the standard output.
 // It internally converts the struct to a map and then prints it using PrintMap.
+// Fields tagged `ulog:"redact"` are masked when the default logger has a Redactor.
 func PrintStruct(data interface{}) {
-	mapData, err := ConvertStructToMap(data)
+	mapData, err := ConvertStructToMap(printRedact(data))
 	if err != nil {
 		fmt.Println("Error converting struct to map:", err)
 		return
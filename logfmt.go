@@ -0,0 +1,108 @@
+package ulog
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// LogfmtEncoder renders each record as a logfmt line:
+//
+//	ts=2025-05-01T15:04:05Z level=warning tag=DB msg="connection lost" user=42
+//
+// Nested maps in fields are flattened into dotted keys such as db.host.
+type LogfmtEncoder struct {
+	// TimeFormat is the layout of the "ts" value. Defaults to time.RFC3339.
+	TimeFormat string
+}
+
+// Encode implements Encoder
+func (e LogfmtEncoder) Encode(buf *bytes.Buffer, r *Record) error {
+	layout := e.TimeFormat
+	if layout == "" {
+		layout = time.RFC3339
+	}
+
+	if !r.Time.IsZero() {
+		buf.WriteString("ts=")
+		buf.WriteString(logfmtValue(r.Time.Format(layout)))
+		buf.WriteByte(' ')
+	}
+	buf.WriteString("level=")
+	buf.WriteString(r.Level.String())
+	if r.Tag != "" {
+		buf.WriteString(" tag=")
+		buf.WriteString(logfmtValue(r.Tag))
+	}
+	if r.Caller != nil {
+		buf.WriteString(" caller=")
+		buf.WriteString(logfmtValue(r.Caller.Location()))
+		if r.Caller.Function != "" {
+			buf.WriteString(" func=")
+			buf.WriteString(logfmtValue(r.Caller.Function))
+		}
+	}
+	buf.WriteString(" msg=")
+	buf.WriteString(logfmtValue(r.Message))
+	if r.Repeat > 1 {
+		buf.WriteString(" repeat=")
+		buf.WriteString(strconv.Itoa(r.Repeat))
+		if !r.LastTime.IsZero() {
+			buf.WriteString(" last=")
+			buf.WriteString(logfmtValue(r.LastTime.Format(layout)))
+		}
+	}
+	for _, f := range flattenFields(r.Fields) {
+		buf.WriteByte(' ')
+		buf.WriteString(logfmtKey(f.Key))
+		buf.WriteByte('=')
+		buf.WriteString(logfmtValue(logfmtString(f.Value)))
+	}
+	buf.WriteByte('\n')
+	return nil
+}
+
+// logfmtString converts a field value to text. Strings, errors and Stringers are
+// used as-is, other values are formatted with ValueAsString.
+func logfmtString(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case error:
+		return val.Error()
+	case interface{ String() string }:
+		return val.String()
+	case nil:
+		return "null"
+	}
+	return valueAsString(v)
+}
+
+// logfmtKey replaces characters that are not allowed in a logfmt key with underscores
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtValue quotes the value when it is empty or contains spaces, equals signs,
+// quotes or control characters, escaping it the same way as Go string literals
+func logfmtValue(value string) string {
+	if value == "" {
+		return `""`
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r) {
+			return strconv.Quote(value)
+		}
+	}
+	return value
+}
@@ -2,191 +2,524 @@ package ulog
 
 import (
 	"fmt"
+	"io"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
-
-	"github.com/fatih/color"
 )
 
-// Box drawing characters
+// Logger is a utility for logging with box-style outputs and colors.
+//
+// A Logger is safe for concurrent use by multiple goroutines: every box is
+// written with a single Write call while holding the sink's lock, so boxes never
+// interleave, and its settings can be changed at runtime with the Set methods.
+type Logger struct {
+	mu       sync.RWMutex // guards settings
+	s        settings
+	set      settingMask // settings overridden by a child logger
+	level    atomic.Int64
+	levelSet atomic.Bool // whether a child logger overrides the level
+	fields   []Field
+	core     *core
+	parent   *Logger // nil for loggers created by NewLogger
+	name     string  // appended to the inherited tag by Named
+}
+
+// settings holds the rendering options of a Logger
+type settings struct {
+	showTimestamp bool
+	padding       int
+	maxWidth      int
+	style         BoxStyle
+	theme         Theme
+	colorProfile  ColorProfile
+	encoder       Encoder
+	tag           string // used when a message has no tag of its own
+	caller        CallerMode
+	redactor      *Redactor
+	hooks         []Hook             // the inherited hooks followed by the logger's own
+	extractors    []ContextExtractor // the inherited extractors followed by the logger's own
+}
+
+// settingMask records which settings a child logger sets itself instead of
+// inheriting them from its parent. Hooks and context extractors are not in the
+// mask: a child always runs its parent's, followed by its own.
+type settingMask uint16
+
 const (
-	topLeft     = "╭"
-	topRight    = "╮"
-	bottomLeft  = "╰"
-	bottomRight = "╯"
-	horizontal  = "─"
-	vertical    = "│"
+	setTimestamp settingMask = 1 << iota
+	setPadding
+	setMaxWidth
+	setStyle
+	setTheme
+	setColorProfile
+	setEncoder
+	setTag
+	setCaller
+	setRedactor
 )
 
-// Colors for different log levels
-var (
-	warningColor = color.New(color.FgYellow).SprintFunc()
-	messageColor = color.New(color.FgBlue).SprintFunc()
-	infoColor    = color.New(color.Reset).SprintFunc()
-	errorColor   = color.New(color.FgRed).SprintFunc()
-	successColor = color.New(color.FgGreen).SprintFunc()
-	ongoingColor = color.New(color.FgHiYellow).SprintFunc() // Orange-like
-	tagColor     = color.New(color.Bold).SprintFunc()
-)
+// override returns st with the settings in mask taken from own
+func (st settings) override(own settings, mask settingMask) settings {
+	if mask&setTimestamp != 0 {
+		st.showTimestamp = own.showTimestamp
+	}
+	if mask&setPadding != 0 {
+		st.padding = own.padding
+	}
+	if mask&setMaxWidth != 0 {
+		st.maxWidth = own.maxWidth
+	}
+	if mask&setStyle != 0 {
+		st.style = own.style
+	}
+	if mask&setTheme != 0 {
+		st.theme = own.theme
+	}
+	if mask&setColorProfile != 0 {
+		st.colorProfile = own.colorProfile
+	}
+	if mask&setEncoder != 0 {
+		st.encoder = own.encoder
+	}
+	if mask&setTag != 0 {
+		st.tag = own.tag
+	}
+	if mask&setCaller != 0 {
+		st.caller = own.caller
+	}
+	if mask&setRedactor != 0 {
+		st.redactor = own.redactor
+	}
+	// Hooks and extractors are added to the inherited ones instead of replacing them
+	if len(own.hooks) > 0 {
+		st.hooks = append(st.hooks[:len(st.hooks):len(st.hooks)], own.hooks...)
+	}
+	if len(own.extractors) > 0 {
+		st.extractors = append(st.extractors[:len(st.extractors):len(st.extractors)], own.extractors...)
+	}
+	return st
+}
 
-// Logger is a utility for logging with box-style outputs and colors
-type Logger struct {
-	showTimestamp bool
-	padding       int
+// defaultSettings returns the settings of a new Logger
+func defaultSettings() settings {
+	return settings{
+		showTimestamp: true,
+		padding:       1,
+		style:         BoxRounded,
+		theme:         ThemeDark,
+		encoder:       encoderFromEnv(),
+	}
 }
 
-// NewLogger creates a new Logger instance
-func NewLogger(showTimestamp bool, padding int) *Logger {
+// NewLogger creates a new Logger instance.
+// Optional settings such as the output writer can be passed as Options.
+func NewLogger(showTimestamp bool, padding int, opts ...Option) *Logger {
 	if padding < 1 {
 		padding = 1
 	}
-	return &Logger{
-		showTimestamp: showTimestamp,
-		padding:       padding,
+	l := &Logger{
+		s:    defaultSettings(),
+		core: newCore(),
 	}
+	l.s.showTimestamp = showTimestamp
+	l.s.padding = padding
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
 }
 
 // Default logger instance with default settings
 var DefaultLogger = NewLogger(true, 1)
 
-// formatBox creates a box around the given message with the specified color function
-func (l *Logger) formatBox(message string, tag string, colorFunc func(a ...interface{}) string) string {
-	lines := strings.Split(message, "\n")
+// formatBox creates a box around the record's message and fields, styled with the theme of the record's level
+// downgraded to the color profile. When width is positive, content lines are wrapped so that the box fits in width columns.
+func (st settings) formatBox(r *Record, width int, profile ColorProfile) string {
+	theme := st.theme.forLevel(r.Level).convert(profile)
+	tag := r.Tag
+	style := st.style
+
+	// Content is wrapped to the space left inside the borders and padding
+	inner := 0
+	if width > 0 {
+		inner = width - 2*displayWidth(style.Vertical) - st.padding*2
+		if style.borderless() {
+			inner = width - displayWidth(style.Vertical) - st.padding
+		}
+	}
 
-	// Find the longest line to determine box width
+	var lines []string
+	for _, line := range strings.Split(r.Message, "\n") {
+		lines = append(lines, wrapLine(expandTabs(line), inner)...)
+	}
+
+	// Structured fields are shown below the message as aligned "key: value" lines,
+	// with wrapped and multi-line values indented under the first value line
+	fields := flattenFields(r.Fields)
+	keyWidth := 0
+	for _, f := range fields {
+		if w := displayWidth(f.Key); w > keyWidth {
+			keyWidth = w
+		}
+	}
+	valueWidth := inner - keyWidth - 2
+	if inner > 0 && valueWidth < 1 {
+		valueWidth = 1
+	}
+	for _, f := range fields {
+		prefix := f.Key + ":" + strings.Repeat(" ", keyWidth-displayWidth(f.Key)+1)
+		var parts []string
+		for _, line := range strings.Split(fieldValueString(f.Value), "\n") {
+			parts = append(parts, wrapLine(expandTabs(line), valueWidth)...)
+		}
+		for i, part := range parts {
+			if i > 0 {
+				prefix = strings.Repeat(" ", keyWidth+2)
+			}
+			lines = append(lines, prefix+part)
+		}
+	}
+
+	// The header line shows the timestamp, the call site and the repeat count
+	var header []string
+	if st.showTimestamp && !r.Time.IsZero() {
+		header = append(header, r.Time.Format("15:04:05"))
+	}
+	if r.Caller != nil {
+		header = append(header, r.Caller.String())
+	}
+	if note := repeatNote(r, style == BoxASCII); note != "" {
+		header = append(header, note)
+	}
+	timestamp := strings.Join(header, " ")
+
+	// Tags too wide for the box are cut short
+	ellipsis := "…"
+	if style == BoxASCII {
+		ellipsis = "..."
+	}
+
+	if style.borderless() {
+		if width > 0 {
+			tag = truncateWidth(tag, width-2, ellipsis)
+		}
+		return st.formatBorderless(lines, tag, timestamp, width, theme)
+	}
+	if width > 0 {
+		tag = truncateWidth(tag, inner-4, ellipsis)
+	}
+
+	var headerLines []string
+	if timestamp != "" {
+		headerLines = wrapLine(timestamp, inner)
+	}
+
+	// Find the widest line to determine box width, measured in terminal cells
 	maxLength := 0
-	for _, line := range lines {
-		if len(line) > maxLength {
-			maxLength = len(line)
+	for _, line := range append(headerLines, lines...) {
+		if w := displayWidth(line); w > maxLength {
+			maxLength = w
 		}
 	}
 
 	// Add space for tag if provided
+	tagWidth := displayWidth(tag)
 	if tag != "" {
-		if len(tag)+4 > maxLength {
-			maxLength = len(tag) + 4
+		if tagWidth+4 > maxLength {
+			maxLength = tagWidth + 4
 		}
 	}
 
 	// Add padding
-	maxLength += l.padding * 2
+	maxLength += st.padding * 2
 
 	// Create the box
 	var result strings.Builder
 
 	// Top border with tag if provided
-	topBorder := topLeft + strings.Repeat(horizontal, maxLength) + topRight
 	if tag != "" {
-		tagDisplay := " " + tagColor(tag) + " "
-		topBorderParts := strings.SplitN(topBorder, "", 2)
-		result.WriteString(colorFunc(topBorderParts[0]+tagDisplay+topBorderParts[1]) + "\n")
+		result.WriteString(theme.Border.paint(style.TopLeft+" ") + theme.Tag.paint(tag) +
+			theme.Border.paint(" "+strings.Repeat(style.Horizontal, maxLength-tagWidth-2)+style.TopRight) + "\n")
 	} else {
-		result.WriteString(colorFunc(topBorder) + "\n")
+		result.WriteString(theme.Border.paint(style.TopLeft+strings.Repeat(style.Horizontal, maxLength)+style.TopRight) + "\n")
 	}
 
-	// Add timestamp if enabled
-	if l.showTimestamp {
-		timestamp := time.Now().Format("15:04:05")
-		paddedLine := vertical + strings.Repeat(" ", l.padding) + timestamp
-		paddedLine += strings.Repeat(" ", maxLength-len(timestamp)) + vertical
-		result.WriteString(colorFunc(paddedLine) + "\n")
+	// Add timestamp and caller if enabled
+	for _, line := range headerLines {
+		result.WriteString(st.boxLine(line, maxLength, theme.Border, theme.Timestamp) + "\n")
 	}
 
-	// Message lines
+	// Message and field lines
 	for _, line := range lines {
-		paddedLine := vertical + strings.Repeat(" ", l.padding) + line
-		paddedLine += strings.Repeat(" ", maxLength-len(line)) + vertical
-		result.WriteString(colorFunc(paddedLine) + "\n")
+		result.WriteString(st.boxLine(line, maxLength, theme.Border, theme.Body) + "\n")
 	}
 
 	// Bottom border
-	result.WriteString(colorFunc(bottomLeft + strings.Repeat(horizontal, maxLength) + bottomRight))
+	result.WriteString(theme.Border.paint(style.BottomLeft + strings.Repeat(style.Horizontal, maxLength) + style.BottomRight))
 
 	return result.String()
 }
 
-// Warning logs a warning message in yellow
-func (l *Logger) Warning(message string, tag ...string) {
-	tagStr := ""
-	if len(tag) > 0 {
-		tagStr = tag[0]
+// formatBorderless renders the content without a border: a header line with the
+// tag and timestamp followed by the content lines prefixed with the style's Vertical.
+// When width is positive, the timestamp moves to its own lines if the header does not fit.
+func (st settings) formatBorderless(lines []string, tag, timestamp string, width int, theme LevelTheme) string {
+	var result []string
+	var stamps []string
+	if timestamp != "" {
+		stamps = wrapLine(timestamp, width)
 	}
-	fmt.Println(l.formatBox(message, tagStr, warningColor))
+	if tag != "" {
+		header := theme.Border.paint("[") + theme.Tag.paint(tag) + theme.Border.paint("]")
+		if len(stamps) > 0 && (width <= 0 || displayWidth(tag)+3+displayWidth(stamps[0]) <= width) {
+			header += " " + theme.Timestamp.paint(stamps[0])
+			stamps = stamps[1:]
+		}
+		result = append(result, header)
+	}
+	for _, stamp := range stamps {
+		result = append(result, theme.Timestamp.paint(stamp))
+	}
+	for _, line := range lines {
+		result = append(result, theme.Border.paint(st.style.Vertical)+strings.Repeat(" ", st.padding)+theme.Body.paint(line))
+	}
+	return strings.Join(result, "\n")
 }
 
-// Message logs a message in blue
-func (l *Logger) Message(message string, tag ...string) {
-	tagStr := ""
-	if len(tag) > 0 {
-		tagStr = tag[0]
+// boxLine pads a line of content to the inner box width and adds the side borders
+func (st settings) boxLine(line string, innerWidth int, border, body Style) string {
+	fill := innerWidth - st.padding - displayWidth(line)
+	if fill < 0 {
+		fill = 0
 	}
-	fmt.Println(l.formatBox(message, tagStr, messageColor))
+	return border.paint(st.style.Vertical) + strings.Repeat(" ", st.padding) + body.paint(line) +
+		strings.Repeat(" ", fill) + border.paint(st.style.Vertical)
 }
 
-// Info logs an info message in default terminal color
-func (l *Logger) Info(message string, tag ...string) {
-	tagStr := ""
-	if len(tag) > 0 {
-		tagStr = tag[0]
+// clone returns a child logger. The child shares the parent's core, so their
+// boxes never interleave and the setters of the core act on both, and inherits
+// the parent's level and settings, including later changes, until it overrides
+// them with its own Set calls.
+func (l *Logger) clone() *Logger {
+	return &Logger{
+		fields: l.fields,
+		core:   l.core,
+		parent: l,
 	}
-	fmt.Println(l.formatBox(message, tagStr, infoColor))
 }
 
-// Error logs an error message in red
-func (l *Logger) Error(message string, tag ...string) {
-	tagStr := ""
-	if len(tag) > 0 {
-		tagStr = tag[0]
+// settings returns a snapshot of the logger's rendering options, resolving
+// inherited settings through its parents
+func (l *Logger) settings() settings {
+	l.mu.RLock()
+	own, mask := l.s, l.set
+	l.mu.RUnlock()
+	if l.parent == nil {
+		return own
 	}
-	fmt.Println(l.formatBox(message, tagStr, errorColor))
+	st := l.parent.settings()
+	if l.name != "" {
+		st.tag = joinTag(st.tag, l.name)
+	}
+	return st.override(own, mask)
 }
 
-// Success logs a success message in green
-func (l *Logger) Success(message string, tag ...string) {
-	tagStr := ""
-	if len(tag) > 0 {
-		tagStr = tag[0]
+// SetTimestamp enables or disables the timestamp line in each box
+func (l *Logger) SetTimestamp(show bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.s.showTimestamp = show
+	l.set |= setTimestamp
+}
+
+// SetPadding sets the padding inside the box (minimum 1)
+func (l *Logger) SetPadding(padding int) {
+	if padding < 1 {
+		padding = 1
 	}
-	fmt.Println(l.formatBox(message, tagStr, successColor))
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.s.padding = padding
+	l.set |= setPadding
 }
 
-// Ongoing logs an ongoing operation message in orange-like color
-func (l *Logger) Ongoing(message string, tag ...string) {
-	tagStr := ""
+// SetMaxWidth sets the maximum box width in columns. Boxes are always fitted to
+// the terminal width; the maximum also applies when the output is not a terminal.
+// Zero removes the limit.
+func (l *Logger) SetMaxWidth(width int) {
+	if width < 0 {
+		width = 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.s.maxWidth = width
+	l.set |= setMaxWidth
+}
+
+// SetLevel sets the minimum level a message needs to be printed
+func (l *Logger) SetLevel(level Level) {
+	l.level.Store(int64(level))
+	l.levelSet.Store(true)
+}
+
+// Level returns the minimum level a message needs to be printed.
+// A child logger uses its parent's level until SetLevel is called on it.
+func (l *Logger) Level() Level {
+	if l.parent != nil && !l.levelSet.Load() {
+		return l.parent.Level()
+	}
+	return Level(l.level.Load())
+}
+
+// Enabled reports whether messages of the given level are printed by the logger
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.Level()
+}
+
+// SetOutput sets the writer of the console sink, which log boxes are written to.
+// The console sink belongs to the logger tree, so calling SetOutput on a child
+// also changes the output of its parent and siblings.
+func (l *Logger) SetOutput(w io.Writer) {
+	if w == nil {
+		return
+	}
+	l.core.mu.Lock()
+	console := l.core.consoleSink()
+	l.core.mu.Unlock()
+
+	console.mu.Lock()
+	defer console.mu.Unlock()
+	console.Writer = w
+	console.outProfile = ProfileAuto
+}
+
+// SetErrorOutput sets a separate writer for Error and Warning boxes of the console sink.
+// Passing nil sends them to the regular output writer again. Like SetOutput, it
+// applies to the whole logger tree.
+func (l *Logger) SetErrorOutput(w io.Writer) {
+	l.core.mu.Lock()
+	console := l.core.consoleSink()
+	l.core.mu.Unlock()
+
+	console.mu.Lock()
+	defer console.mu.Unlock()
+	console.ErrorWriter = w
+	console.errProfile = ProfileAuto
+}
+
+// log builds a record for the message and writes it.
+// Messages below the logger's level are dropped before any formatting is done.
+func (l *Logger) log(level Level, message string, tag []string) {
+	if !l.Enabled(level) {
+		return
+	}
+	l.write(l.newRecord(level, message, tag))
+}
+
+// logf formats and logs a message, skipping the formatting when the level is disabled
+func (l *Logger) logf(level Level, format string, args []any) {
+	if !l.Enabled(level) {
+		return
+	}
+	l.write(l.newRecord(level, fmt.Sprintf(format, args...), nil))
+}
+
+// newRecord builds the record of a message. It must be called directly by log,
+// logf or logCtx so that the caller annotation skips the right number of frames.
+func (l *Logger) newRecord(level Level, message string, tag []string) *Record {
+	r := &Record{
+		Time:    time.Now(),
+		Level:   level,
+		Message: message,
+		Fields:  l.fields,
+		Caller:  callerAt(callerDepth, l.settings().caller),
+	}
 	if len(tag) > 0 {
-		tagStr = tag[0]
+		r.Tag = tag[0]
+	}
+	return r
+}
+
+// write sends the record to every sink of the logger, applying the logger's
+// tag to records without one
+func (l *Logger) write(r *Record) {
+	st := l.settings()
+	if r.Tag == "" {
+		r.Tag = st.tag
 	}
-	fmt.Println(l.formatBox(message, tagStr, ongoingColor))
+	l.core.write(r, st)
+}
+
+// Warning logs a warning message in yellow at LevelWarning
+func (l *Logger) Warning(message string, tag ...string) {
+	l.log(LevelWarning, message, tag)
+}
+
+// Message logs a message in blue at LevelDebug
+func (l *Logger) Message(message string, tag ...string) {
+	l.log(LevelDebug, message, tag)
+}
+
+// Info logs an info message in default terminal color at LevelInfo
+func (l *Logger) Info(message string, tag ...string) {
+	l.log(LevelInfo, message, tag)
+}
+
+// Error logs an error message in red at LevelError
+func (l *Logger) Error(message string, tag ...string) {
+	l.log(LevelError, message, tag)
+}
+
+// Success logs a success message in green at LevelSuccess
+func (l *Logger) Success(message string, tag ...string) {
+	l.log(LevelSuccess, message, tag)
+}
+
+// Ongoing logs an ongoing operation message in orange at LevelOngoing
+func (l *Logger) Ongoing(message string, tag ...string) {
+	l.log(LevelOngoing, message, tag)
 }
 
 // Global convenience functions that use the default logger
 
 // Warning logs a warning message in yellow using the default logger
 func Warning(message string, tag ...string) {
-	DefaultLogger.Warning(message, tag...)
+	DefaultLogger.log(LevelWarning, message, tag)
 }
 
 // Message logs a message in blue using the default logger
 func Message(message string, tag ...string) {
-	DefaultLogger.Message(message, tag...)
+	DefaultLogger.log(LevelDebug, message, tag)
 }
 
 // Info logs an info message in default terminal color using the default logger
 func Info(message string, tag ...string) {
-	DefaultLogger.Info(message, tag...)
+	DefaultLogger.log(LevelInfo, message, tag)
 }
 
 // Error logs an error message in red using the default logger
 func Error(message string, tag ...string) {
-	DefaultLogger.Error(message, tag...)
+	DefaultLogger.log(LevelError, message, tag)
 }
 
 // Success logs a success message in green using the default logger
 func Success(message string, tag ...string) {
-	DefaultLogger.Success(message, tag...)
+	DefaultLogger.log(LevelSuccess, message, tag)
 }
 
-// Ongoing logs an ongoing operation message in orange-like color using the default logger
+// Ongoing logs an ongoing operation message in orange using the default logger
 func Ongoing(message string, tag ...string) {
-	DefaultLogger.Ongoing(message, tag...)
+	DefaultLogger.log(LevelOngoing, message, tag)
+}
+
+// With returns a child of the default logger that adds the given key/value pairs to every message
+func With(args ...any) *Logger {
+	return DefaultLogger.With(args...)
+}
+
+// SetLevel sets the minimum level printed by the default logger
+func SetLevel(level Level) {
+	DefaultLogger.SetLevel(level)
 }
@@ -0,0 +1,162 @@
+package ulog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recorder is a writer that keeps every Write call as a separate chunk
+type recorder struct {
+	mu     sync.Mutex
+	chunks []string
+}
+
+func (w *recorder) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.chunks = append(w.chunks, string(p))
+	return len(p), nil
+}
+
+func (w *recorder) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return strings.Join(w.chunks, "")
+}
+
+// testLogger returns a logger drawing uncolored boxes to w
+func testLogger(w io.Writer, opts ...Option) *Logger {
+	opts = append([]Option{WithOutput(w), WithColorProfile(ProfileNoColor), WithEncoder(BoxEncoder{})}, opts...)
+	return NewLogger(false, 1, opts...)
+}
+
+// splitBoxes splits the output of a logger using BoxRounded into boxes
+func splitBoxes(t *testing.T, out string) [][]string {
+	t.Helper()
+	if out == "" {
+		return nil
+	}
+	var boxes [][]string
+	var box []string
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if strings.HasPrefix(line, BoxRounded.TopLeft) {
+			if box != nil {
+				t.Fatalf("box not closed before %q", line)
+			}
+			box = []string{}
+		}
+		if box == nil {
+			t.Fatalf("line outside of a box: %q", line)
+		}
+		box = append(box, line)
+		if strings.HasPrefix(line, BoxRounded.BottomLeft) {
+			boxes = append(boxes, box)
+			box = nil
+		}
+	}
+	if box != nil {
+		t.Fatalf("box not closed: %q", box)
+	}
+	return boxes
+}
+
+// checkBox fails unless box is a whole box whose lines all have the same width
+func checkBox(t *testing.T, box []string) {
+	t.Helper()
+	if len(box) < 3 {
+		t.Fatalf("box has %d lines: %q", len(box), box)
+	}
+	last := len(box) - 1
+	if !strings.HasSuffix(box[0], BoxRounded.TopRight) || !strings.HasSuffix(box[last], BoxRounded.BottomRight) {
+		t.Fatalf("box corners missing: %q", box)
+	}
+	width := displayWidth(box[0])
+	for _, line := range box[1:] {
+		if line != box[last] && (!strings.HasPrefix(line, BoxRounded.Vertical) || !strings.HasSuffix(line, BoxRounded.Vertical)) {
+			t.Fatalf("box side missing in %q", line)
+		}
+		if w := displayWidth(line); w != width {
+			t.Fatalf("line %q is %d cells wide, want %d:\n%s", line, w, width, strings.Join(box, "\n"))
+		}
+	}
+}
+
+func TestLoggerConcurrentUse(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	var out1, out2 recorder
+	l := testLogger(&out1)
+
+	const goroutines, messages = 8, 100
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < messages; i++ {
+				msg := fmt.Sprintf("goroutine %d message %d", g, i)
+				switch i % 6 {
+				case 0:
+					l.Warning(msg, "WARN")
+				case 1:
+					l.Message(msg)
+				case 2:
+					l.Info(msg, "INFO")
+				case 3:
+					l.Error(msg)
+				case 4:
+					l.Success(msg, "OK")
+				case 5:
+					l.Ongoing(msg)
+				}
+			}
+		}()
+	}
+
+	stop := make(chan struct{})
+	var setters sync.WaitGroup
+	setters.Add(1)
+	go func() {
+		defer setters.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			l.SetTimestamp(i%2 == 0)
+			l.SetPadding(1 + i%3)
+			l.SetLevel(Level(i % 2))
+			if i%2 == 0 {
+				l.SetOutput(&out1)
+			} else {
+				l.SetOutput(&out2)
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(stop)
+	setters.Wait()
+
+	total := 0
+	for _, w := range []*recorder{&out1, &out2} {
+		for _, chunk := range w.chunks {
+			boxes := splitBoxes(t, chunk)
+			if len(boxes) != 1 {
+				t.Fatalf("write contains %d boxes, want 1:\n%s", len(boxes), chunk)
+			}
+			checkBox(t, boxes[0])
+			if !strings.Contains(chunk, "goroutine ") {
+				t.Fatalf("box without a message:\n%s", chunk)
+			}
+		}
+		total += len(splitBoxes(t, w.String()))
+	}
+	// Only the Message calls, one in six, can be filtered by the level changes
+	if min := goroutines * (messages - (messages+4)/6); total < min {
+		t.Errorf("got %d boxes, want at least %d", total, min)
+	}
+}
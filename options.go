@@ -0,0 +1,152 @@
+package ulog
+
+import (
+	"io"
+	"time"
+)
+
+// Option configures optional Logger settings when passed to NewLogger
+type Option func(*Logger)
+
+// WithOutput sets the writer log boxes are written to.
+// By default a Logger writes to os.Stdout.
+func WithOutput(w io.Writer) Option {
+	return func(l *Logger) {
+		l.SetOutput(w)
+	}
+}
+
+// WithErrorOutput sets a separate writer for Error and Warning boxes,
+// for example os.Stderr. When unset they go to the regular output writer.
+func WithErrorOutput(w io.Writer) Option {
+	return func(l *Logger) {
+		l.SetErrorOutput(w)
+	}
+}
+
+// WithLevel sets the minimum level a message needs to be printed.
+// The default is LevelDebug, which prints everything.
+func WithLevel(level Level) Option {
+	return func(l *Logger) {
+		l.SetLevel(level)
+	}
+}
+
+// WithMaxWidth sets the maximum box width in columns, see Logger.SetMaxWidth
+func WithMaxWidth(width int) Option {
+	return func(l *Logger) {
+		l.SetMaxWidth(width)
+	}
+}
+
+// WithBoxStyle sets the characters used to draw boxes, e.g. BoxASCII
+func WithBoxStyle(style BoxStyle) Option {
+	return func(l *Logger) {
+		l.SetBoxStyle(style)
+	}
+}
+
+// WithTheme sets the colors used to draw boxes, e.g. ThemeLight
+func WithTheme(theme Theme) Option {
+	return func(l *Logger) {
+		l.SetTheme(theme)
+	}
+}
+
+// WithColorProfile overrides the detected color profile of the output writers,
+// e.g. ProfileNoColor to never use colors or ProfileTrueColor to always use them
+func WithColorProfile(profile ColorProfile) Option {
+	return func(l *Logger) {
+		l.SetColorProfile(profile)
+	}
+}
+
+// WithEncoder sets the encoder used to render records, e.g. JSONEncoder{}.
+// It takes precedence over the ULOG_FORMAT environment variable.
+func WithEncoder(enc Encoder) Option {
+	return func(l *Logger) {
+		l.SetEncoder(enc)
+	}
+}
+
+// WithSinks replaces the default console output with the given sinks
+func WithSinks(sinks ...*Sink) Option {
+	return func(l *Logger) {
+		l.SetSinks(sinks...)
+	}
+}
+
+// WithErrorHandler sets the function called when writing to a sink fails
+func WithErrorHandler(handler func(error)) Option {
+	return func(l *Logger) {
+		l.SetErrorHandler(handler)
+	}
+}
+
+// WithAsync makes the logger write records from a background goroutine
+func WithAsync(opts AsyncOptions) Option {
+	return func(l *Logger) {
+		l.SetAsync(opts)
+	}
+}
+
+// WithExitFunc replaces os.Exit as the function called by Fatal
+func WithExitFunc(exit func(code int)) Option {
+	return func(l *Logger) {
+		l.SetExitFunc(exit)
+	}
+}
+
+// WithDefaultTag sets the tag shown on messages logged without a tag of their own.
+// Use the WithTag method for a tagged child of an existing logger.
+func WithDefaultTag(tag string) Option {
+	return func(l *Logger) {
+		l.SetTag(tag)
+	}
+}
+
+// WithCaller annotates messages with the file, line and optionally function that logged them
+func WithCaller(mode CallerMode) Option {
+	return func(l *Logger) {
+		l.SetCaller(mode)
+	}
+}
+
+// WithHooks adds hooks that intercept the logger's records
+func WithHooks(hooks ...Hook) Option {
+	return func(l *Logger) {
+		for _, h := range hooks {
+			l.AddHook(h)
+		}
+	}
+}
+
+// WithSampling limits repeated records as described by SamplingOptions
+func WithSampling(opts SamplingOptions) Option {
+	return func(l *Logger) {
+		l.SetSampling(&opts)
+	}
+}
+
+// WithCollapse collapses identical consecutive messages into one with a repeat count
+func WithCollapse(timeout time.Duration) Option {
+	return func(l *Logger) {
+		l.SetCollapse(timeout)
+	}
+}
+
+// WithRedactor masks secrets and personal data in messages and fields, e.g. WithRedactor(NewRedactor())
+func WithRedactor(rd *Redactor) Option {
+	return func(l *Logger) {
+		l.SetRedactor(rd)
+	}
+}
+
+// WithContextExtractors adds extractors that turn context values into fields
+func WithContextExtractors(fns ...ContextExtractor) Option {
+	return func(l *Logger) {
+		for _, fn := range fns {
+			l.AddContextExtractor(fn)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package ulog
+
+import "time"
+
+// Record is a single log event: the message together with its level, tag,
+// time and structured fields
+type Record struct {
+	Time    time.Time // zero when unknown, e.g. for a slog.Record without a time; encoders then omit it
+	Level   Level
+	Tag     string
+	Message string
+	Fields  []Field
+	Caller  *Caller // nil unless the logger annotates the call site
+
+	// Repeat is the number of identical consecutive messages collapsed into this
+	// record, and LastTime the time of the last one. Repeat is 0 or 1 for a single message.
+	Repeat   int
+	LastTime time.Time
+
+	hints *renderHints
+}
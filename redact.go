@@ -0,0 +1,542 @@
+package ulog
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Detector finds one kind of secret or personal data in text
+type Detector struct {
+	// Name identifies the kind of data, e.g. "email". It is shown by MaskKind.
+	Name string
+
+	// Pattern matches the data. When it has a capture group, only the first group is masked.
+	Pattern *regexp.Regexp
+
+	// Validate, when set, rejects matches that are not real secrets, e.g. numbers failing the Luhn check
+	Validate func(match string) bool
+}
+
+// Built-in detectors
+var (
+	// DetectBearerToken masks the token of "Bearer <token>" authorization values
+	DetectBearerToken = Detector{Name: "bearer", Pattern: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`)}
+
+	// DetectAWSKey masks AWS access key IDs and secret access keys assigned to an aws_secret_access_key name
+	DetectAWSKey = Detector{Name: "aws-key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[A-Z0-9]{16}\b|(?i)aws_?secret_?access_?key\W{1,3}([A-Za-z0-9/+=]{40})`)}
+
+	// DetectJWT masks JSON Web Tokens
+	DetectJWT = Detector{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)}
+
+	// DetectEmail masks email addresses
+	DetectEmail = Detector{Name: "email", Pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)}
+
+	// DetectCreditCard masks card numbers of 13 to 19 digits, optionally grouped with spaces or dashes,
+	// that start with the issuer prefix of a major card network, have its length and pass the Luhn
+	// check, so that timestamps and numeric IDs are left alone
+	DetectCreditCard = Detector{Name: "credit-card", Pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), Validate: cardNumberValid}
+)
+
+// DefaultDetectors returns the built-in detectors
+func DefaultDetectors() []Detector {
+	return []Detector{DetectBearerToken, DetectAWSKey, DetectJWT, DetectEmail, DetectCreditCard}
+}
+
+// DefaultRedactKeys are the key names whose values NewRedactor masks entirely
+var DefaultRedactKeys = []string{"password", "passwd", "secret", "authorization", "apikey", "accesstoken", "refreshtoken", "privatekey"}
+
+// MaskStyle selects how redacted data is replaced
+type MaskStyle int
+
+const (
+	// MaskFull replaces the data with "[REDACTED]"
+	MaskFull MaskStyle = iota
+
+	// MaskKind replaces the data with its kind, e.g. "[REDACTED:email]"
+	MaskKind
+
+	// MaskPartial keeps the last 4 characters of values longer than 8 characters, e.g. "****4242"
+	MaskPartial
+)
+
+// Redactor masks secrets and personal data in log messages, fields and the
+// data-structure printers. Data is masked when:
+//
+//   - text matches one of the Detectors
+//   - a field or map key contains one of the Keys, ignoring case, "-" and "_",
+//     so "password" also matches "db_password"
+//   - a struct field has the `ulog:"redact"` tag
+//
+// A Redactor must not be modified after it has been passed to a Logger.
+type Redactor struct {
+	Detectors []Detector
+	Keys      []string
+	Mask      MaskStyle
+
+	// MaskFunc, when set, replaces Mask. It receives the kind of data (the
+	// detector name, key or struct field name) and the value to mask.
+	MaskFunc func(kind, value string) string
+
+	types    sync.Map // reflect.Type -> bool, whether values of the type need to be converted
+	keysOnce sync.Once
+	keys     []string // Keys normalized
+}
+
+// NewRedactor returns a Redactor with the built-in detectors and DefaultRedactKeys
+func NewRedactor() *Redactor {
+	return &Redactor{
+		Detectors: DefaultDetectors(),
+		Keys:      append([]string(nil), DefaultRedactKeys...),
+	}
+}
+
+// mask returns the replacement of a redacted value
+func (rd *Redactor) mask(kind, value string) string {
+	if rd.MaskFunc != nil {
+		return rd.MaskFunc(kind, value)
+	}
+	switch rd.Mask {
+	case MaskKind:
+		return "[REDACTED:" + kind + "]"
+	case MaskPartial:
+		n := utf8.RuneCountInString(value)
+		if n <= 8 {
+			return "****"
+		}
+		return "****" + string([]rune(value)[n-4:])
+	}
+	return "[REDACTED]"
+}
+
+// String masks the data found by the detectors in s
+func (rd *Redactor) String(s string) string {
+	for _, d := range rd.Detectors {
+		if d.Pattern == nil {
+			continue
+		}
+		s = rd.apply(d, s)
+	}
+	return s
+}
+
+// apply masks the matches of one detector
+func (rd *Redactor) apply(d Detector, s string) string {
+	matches := d.Pattern.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		// Mask only the first capture group when the pattern has one and it matched
+		if len(m) >= 4 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		if d.Validate != nil && !d.Validate(s[start:end]) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(rd.mask(d.Name, s[start:end]))
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// sensitiveKey reports whether values stored under key must be masked
+func (rd *Redactor) sensitiveKey(key string) bool {
+	k := normalizeKey(key)
+	for _, rule := range rd.normalizedKeys() {
+		if strings.Contains(k, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizedKeys returns the non-empty Keys, normalized once
+func (rd *Redactor) normalizedKeys() []string {
+	rd.keysOnce.Do(func() {
+		for _, rule := range rd.Keys {
+			if rule = normalizeKey(rule); rule != "" {
+				rd.keys = append(rd.keys, rule)
+			}
+		}
+	})
+	return rd.keys
+}
+
+// keyReplacer removes the separators ignored when matching keys
+var keyReplacer = strings.NewReplacer("-", "", "_", "")
+
+// normalizeKey lower-cases a key and removes "-" and "_"
+func normalizeKey(key string) string {
+	return keyReplacer.Replace(strings.ToLower(key))
+}
+
+// maskValue masks a whole value
+func (rd *Redactor) maskValue(kind string, v any) string {
+	return rd.mask(kind, fmt.Sprint(v))
+}
+
+// Fields returns a copy of fields with sensitive keys and values masked
+func (rd *Redactor) Fields(fields []Field) []Field {
+	return rd.fields(fields, &walk{})
+}
+
+func (rd *Redactor) fields(fields []Field, w *walk) []Field {
+	if len(fields) == 0 {
+		return fields
+	}
+	out := make([]Field, len(fields))
+	for i, f := range fields {
+		out[i].Key = f.Key
+		if rd.sensitiveKey(f.Key) {
+			out[i].Value = rd.maskValue(f.Key, f.Value)
+		} else {
+			out[i].Value = rd.value(f.Value, w)
+		}
+	}
+	return out
+}
+
+// cycleMarker replaces a pointer, map or slice that refers back to a value containing it
+const cycleMarker = "<cycle>"
+
+// visit identifies a pointer, map or slice being redacted
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// walk tracks the pointers, maps and slices between the value passed to Value
+// and the value being redacted, so that cyclic data terminates
+type walk struct {
+	path map[visit]bool
+}
+
+// enter adds rv to the path, reporting false when it is already on it
+func (w *walk) enter(rv reflect.Value) bool {
+	v := visit{rv.Pointer(), rv.Type()}
+	if w.path[v] {
+		return false
+	}
+	if w.path == nil {
+		w.path = make(map[visit]bool)
+	}
+	w.path[v] = true
+	return true
+}
+
+// leave removes rv from the path
+func (w *walk) leave(rv reflect.Value) {
+	delete(w.path, visit{rv.Pointer(), rv.Type()})
+}
+
+// Value returns a copy of v with sensitive data masked. Strings and errors are
+// scanned by the detectors, maps and slices are redacted recursively with the
+// key rules applied to maps with string keys, and structs with `ulog:"redact"`
+// tags, sensitive field names or strings are converted to maps with the
+// sensitive fields masked. A pointer, map or slice that refers back to a value
+// containing it is replaced by "<cycle>". Other values are returned unchanged.
+func (rd *Redactor) Value(v any) any {
+	return rd.value(v, &walk{})
+}
+
+func (rd *Redactor) value(v any, w *walk) any {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return rd.String(val)
+	case error:
+		return rd.String(val.Error())
+	case map[string]any:
+		if !w.enter(reflect.ValueOf(val)) {
+			return cycleMarker
+		}
+		defer w.leave(reflect.ValueOf(val))
+		return rd.mapValue(val, w)
+	case Fields:
+		if !w.enter(reflect.ValueOf(val)) {
+			return cycleMarker
+		}
+		defer w.leave(reflect.ValueOf(val))
+		return Fields(rd.mapValue(val, w))
+	case []Field:
+		return rd.fields(val, w)
+	case []string:
+		out := make([]string, len(val))
+		for i, item := range val {
+			out[i] = rd.String(item)
+		}
+		return out
+	}
+	rv := reflect.ValueOf(v)
+	if rd.needsConversion(rv.Type()) {
+		return rd.reflectValue(rv, w)
+	}
+	return v
+}
+
+// mapValue redacts a map by key name and value
+func (rd *Redactor) mapValue(m map[string]any, w *walk) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		if rd.sensitiveKey(k) {
+			out[k] = rd.maskValue(k, v)
+		} else {
+			out[k] = rd.value(v, w)
+		}
+	}
+	return out
+}
+
+// needsConversion reports whether values of type t may contain strings,
+// string-keyed maps or struct fields that must be redacted
+func (rd *Redactor) needsConversion(t reflect.Type) bool {
+	if cached, ok := rd.types.Load(t); ok {
+		return cached.(bool)
+	}
+	needs := rd.typeNeeds(t, make(map[reflect.Type]bool))
+	rd.types.Store(t, needs)
+	return needs
+}
+
+// typeNeeds implements needsConversion. The types in visiting are being
+// inspected and count as not needing conversion, so that recursive types
+// terminate; only results that cannot depend on them are cached.
+func (rd *Redactor) typeNeeds(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if cached, ok := rd.types.Load(t); ok {
+		return cached.(bool)
+	}
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+	needs := false
+	switch t.Kind() {
+	case reflect.String:
+		needs = len(rd.Detectors) > 0
+	case reflect.Interface:
+		needs = true
+	case reflect.Map:
+		needs = t.Key().Kind() == reflect.String && len(rd.normalizedKeys()) > 0 || rd.typeNeeds(t.Elem(), visiting)
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		needs = rd.typeNeeds(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if redactTag(f) || rd.sensitiveKey(fieldName(f)) || rd.typeNeeds(f.Type, visiting) {
+				needs = true
+				break
+			}
+		}
+	}
+	if needs {
+		rd.types.Store(t, true)
+	}
+	return needs
+}
+
+// reflectValue converts structs to maps with sensitive fields masked, keeping
+// the structure of pointers, slices and maps around them
+func (rd *Redactor) reflectValue(rv reflect.Value, w *walk) any {
+	switch rv.Kind() {
+	case reflect.String:
+		return rd.String(rv.String())
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		if rv.Kind() == reflect.Pointer {
+			if !w.enter(rv) {
+				return cycleMarker
+			}
+			defer w.leave(rv)
+		}
+		return rd.value(rv.Elem().Interface(), w)
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice {
+			if rv.IsNil() {
+				return nil
+			}
+			if !w.enter(rv) {
+				return cycleMarker
+			}
+			defer w.leave(rv)
+		}
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = rd.value(rv.Index(i).Interface(), w)
+		}
+		return out
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil
+		}
+		if !w.enter(rv) {
+			return cycleMarker
+		}
+		defer w.leave(rv)
+		out := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			k := fmt.Sprint(iter.Key().Interface())
+			if iter.Key().Kind() == reflect.String && rd.sensitiveKey(k) {
+				out[k] = rd.maskValue(k, iter.Value().Interface())
+			} else {
+				out[k] = rd.value(iter.Value().Interface(), w)
+			}
+		}
+		return out
+	case reflect.Struct:
+		t := rv.Type()
+		out := make(map[string]any, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name := fieldName(f)
+			if name == "-" {
+				continue
+			}
+			value := rv.Field(i).Interface()
+			if redactTag(f) || rd.sensitiveKey(name) {
+				out[name] = rd.maskValue(name, value)
+			} else {
+				out[name] = rd.value(value, w)
+			}
+		}
+		return out
+	}
+	return rv.Interface()
+}
+
+// redactTag reports whether a struct field is tagged `ulog:"redact"`
+func redactTag(f reflect.StructField) bool {
+	for _, opt := range strings.Split(f.Tag.Get("ulog"), ",") {
+		if strings.TrimSpace(opt) == "redact" {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldName returns the JSON name of a struct field, or its Go name
+func fieldName(f reflect.StructField) string {
+	if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name != "" {
+		return name
+	}
+	return f.Name
+}
+
+// cardRanges lists the issuer prefixes (IIN ranges) of the major card networks
+// and the lengths of their card numbers
+var cardRanges = []struct {
+	low, high int // prefix range, inclusive; both have the same number of digits
+	lengths   []int
+}{
+	{4, 4, []int{13, 16, 19}},                 // Visa
+	{51, 55, []int{16}},                       // Mastercard
+	{2221, 2720, []int{16}},                   // Mastercard
+	{34, 34, []int{15}},                       // American Express
+	{37, 37, []int{15}},                       // American Express
+	{300, 305, []int{14, 15, 16, 17, 18, 19}}, // Diners Club
+	{36, 36, []int{14, 15, 16, 17, 18, 19}},   // Diners Club
+	{38, 39, []int{16, 17, 18, 19}},           // Diners Club
+	{6011, 6011, []int{16, 17, 18, 19}},       // Discover
+	{644, 649, []int{16, 17, 18, 19}},         // Discover
+	{65, 65, []int{16, 17, 18, 19}},           // Discover
+	{3528, 3589, []int{16, 17, 18, 19}},       // JCB
+	{62, 62, []int{16, 17, 18, 19}},           // UnionPay
+}
+
+// cardNumberValid reports whether s, with any separators, is a card number of a
+// known network that passes the Luhn check
+func cardNumberValid(s string) bool {
+	digits := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			digits = append(digits, s[i])
+		}
+	}
+	for _, r := range cardRanges {
+		if !slices.Contains(r.lengths, len(digits)) {
+			continue
+		}
+		prefix, _ := strconv.Atoi(string(digits[:len(strconv.Itoa(r.low))]))
+		if prefix >= r.low && prefix <= r.high {
+			return luhnValid(string(digits))
+		}
+	}
+	return false
+}
+
+// luhnValid reports whether the digits of s pass the Luhn checksum used by card numbers
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
+
+// redactRecord returns a copy of the record with its message and fields redacted
+func (rd *Redactor) redactRecord(r *Record) *Record {
+	out := *r
+	out.Message = rd.String(r.Message)
+	out.Fields = rd.Fields(r.Fields)
+	return &out
+}
+
+// printRedact redacts values shown by the data-structure printers with the
+// redactor of the default logger
+func printRedact(v any) any {
+	if rd := DefaultLogger.settings().redactor; rd != nil {
+		return rd.Value(v)
+	}
+	return v
+}
+
+// SetRedactor masks secrets and personal data in the messages and fields of the
+// logger and its children. Passing nil turns redaction off.
+// The redactor of the default logger also applies to the data-structure printers
+// such as PrintMap and MapAsPrettyString.
+func (l *Logger) SetRedactor(rd *Redactor) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.s.redactor = rd
+	l.set |= setRedactor
+}
+
+// SetRedactor sets the redactor of the default logger and the data-structure printers
+func SetRedactor(rd *Redactor) {
+	DefaultLogger.SetRedactor(rd)
+}
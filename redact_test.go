@@ -0,0 +1,241 @@
+package ulog
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectCreditCard(t *testing.T) {
+	rd := &Redactor{Detectors: []Detector{DetectCreditCard}, Mask: MaskKind}
+	tests := []struct {
+		in, want string
+	}{
+		{"card 4111111111111111", "card [REDACTED:credit-card]"},
+		{"card 4242 4242 4242 4242 declined", "card [REDACTED:credit-card] declined"},
+		{"card 5555-5555-5555-4444", "card [REDACTED:credit-card]"},
+		{"amex 378282246310005", "amex [REDACTED:credit-card]"},
+		{"discover 6011111111111117", "discover [REDACTED:credit-card]"},
+		{"jcb 3530111333300000", "jcb [REDACTED:credit-card]"},
+		// Fails the Luhn check
+		{"card 4111111111111112", "card 4111111111111112"},
+		// Luhn-valid but no card network uses the prefix or length
+		{"id 9111111111111110", "id 9111111111111110"},
+		{"amex length 3782822463100052", "amex length 3782822463100052"},
+		{"ts 1760612345676", "ts 1760612345676"},
+	}
+	for _, tt := range tests {
+		if got := rd.String(tt.in); got != tt.want {
+			t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDetectCreditCardIgnoresTimestamps(t *testing.T) {
+	rd := &Redactor{Detectors: []Detector{DetectCreditCard}}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	for i := int64(0); i < 10000; i++ {
+		ms := strconv.FormatInt(start+i*7919, 10)
+		if got := rd.String(ms); got != ms {
+			t.Fatalf("timestamp %s was masked as %q", ms, got)
+		}
+	}
+}
+
+func TestRedactorKeys(t *testing.T) {
+	rd := NewRedactor()
+	fields := rd.Fields([]Field{
+		F("password", "hunter2"),
+		F("DB_Password", "hunter2"),
+		F("X-Api-Key", "k-123"),
+		F("refresh_token", "r-123"),
+		F("user", "bob"),
+	})
+	want := []string{"[REDACTED]", "[REDACTED]", "[REDACTED]", "[REDACTED]", "bob"}
+	for i, f := range fields {
+		if f.Value != want[i] {
+			t.Errorf("%s = %v, want %s", f.Key, f.Value, want[i])
+		}
+	}
+
+	rd = &Redactor{Keys: []string{"SESSION-ID"}}
+	if got := rd.Fields([]Field{F("session_id", "abc"), F("password", "hunter2")}); got[0].Value != "[REDACTED]" || got[1].Value != "hunter2" {
+		t.Errorf("custom keys redacted %v", got)
+	}
+}
+
+func TestRedactorStringKeyedMaps(t *testing.T) {
+	rd := NewRedactor()
+	header := http.Header{"Authorization": {"Bearer abcdefghijkl"}, "Accept": {"text/plain"}}
+	got, ok := rd.Value(header).(map[string]any)
+	if !ok {
+		t.Fatalf("Value(http.Header) = %T, want map[string]any", rd.Value(header))
+	}
+	if got["Authorization"] != "[REDACTED]" {
+		t.Errorf("Authorization = %v", got["Authorization"])
+	}
+	if accept := fmt.Sprint(got["Accept"]); accept != "[text/plain]" {
+		t.Errorf("Accept = %s", accept)
+	}
+
+	m := rd.Value(map[string]string{"password": "hunter2", "contact": "bob@example.com", "name": "bob"}).(map[string]any)
+	if m["password"] != "[REDACTED]" || m["contact"] != "[REDACTED]" || m["name"] != "bob" {
+		t.Errorf("map[string]string redacted to %v", m)
+	}
+
+	// Maps without string keys keep their keys but have their values scanned
+	ids := rd.Value(map[int]string{1: "bob@example.com"}).(map[string]any)
+	if ids["1"] != "[REDACTED]" {
+		t.Errorf("map[int]string redacted to %v", ids)
+	}
+}
+
+func TestRedactorSlices(t *testing.T) {
+	type Email string
+	rd := &Redactor{Detectors: DefaultDetectors(), Mask: MaskKind}
+
+	if got := rd.Value([]string{"bob@example.com", "ok"}).([]string); got[0] != "[REDACTED:email]" || got[1] != "ok" {
+		t.Errorf("[]string redacted to %q", got)
+	}
+	if got := fmt.Sprint(rd.Value([]Email{"bob@example.com"})); got != "[[REDACTED:email]]" {
+		t.Errorf("[]Email redacted to %s", got)
+	}
+	if got := fmt.Sprint(rd.Value([2][]string{{"Bearer abcdefghijkl"}, {"ok"}})); got != "[[Bearer [REDACTED:bearer]] [ok]]" {
+		t.Errorf("[2][]string redacted to %s", got)
+	}
+	// Values without strings are returned as they are
+	ints := []int{1, 2, 3}
+	if got := rd.Value(ints).([]int); &got[0] != &ints[0] {
+		t.Error("[]int was copied")
+	}
+}
+
+func TestRedactorStructTag(t *testing.T) {
+	type Credentials struct {
+		User   string `json:"user"`
+		Token  string `ulog:"redact"`
+		Secret string `json:"client_secret"`
+		hidden string
+	}
+	rd := NewRedactor()
+	got := rd.Value(Credentials{User: "bob", Token: "t-123", Secret: "s-123", hidden: "h"}).(map[string]any)
+	want := map[string]any{"user": "bob", "Token": "[REDACTED]", "client_secret": "[REDACTED]"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Value(Credentials) = %v, want %v", got, want)
+	}
+	if got := rd.Value(&Credentials{Token: "t-123"}).(map[string]any); got["Token"] != "[REDACTED]" {
+		t.Errorf("Value(*Credentials) = %v", got)
+	}
+}
+
+func TestRedactorCycles(t *testing.T) {
+	type Node struct {
+		Password string
+		Next     *Node
+	}
+	rd := NewRedactor()
+	n := &Node{Password: "hunter2"}
+	n.Next = n
+	got := rd.Value(n).(map[string]any)
+	if got["Password"] != "[REDACTED]" || got["Next"] != cycleMarker {
+		t.Errorf("Value(cyclic node) = %v", got)
+	}
+
+	// A value reached twice without a cycle is redacted both times
+	shared := &Node{Password: "hunter2"}
+	pair := rd.Value([]*Node{shared, shared}).([]any)
+	for _, v := range pair {
+		if m, ok := v.(map[string]any); !ok || m["Password"] != "[REDACTED]" {
+			t.Errorf("shared node redacted to %v", v)
+		}
+	}
+
+	m := map[string]any{"name": "loop"}
+	m["self"] = m
+	if got := rd.Value(m).(map[string]any); got["self"] != cycleMarker || got["name"] != "loop" {
+		t.Errorf("Value(cyclic map) = %v", got)
+	}
+
+	s := []any{"bob@example.com", nil}
+	s[1] = s
+	if got := rd.Value(s).([]any); got[0] != "[REDACTED]" || got[1] != cycleMarker {
+		t.Errorf("Value(cyclic slice) = %v", got)
+	}
+}
+
+func TestRedactorMasks(t *testing.T) {
+	const msg = "mail bob@example.com"
+	tests := []struct {
+		name string
+		rd   *Redactor
+		want string
+	}{
+		{"full", &Redactor{Detectors: []Detector{DetectEmail}}, "mail [REDACTED]"},
+		{"kind", &Redactor{Detectors: []Detector{DetectEmail}, Mask: MaskKind}, "mail [REDACTED:email]"},
+		{"partial", &Redactor{Detectors: []Detector{DetectEmail}, Mask: MaskPartial}, "mail ****.com"},
+		{"func", &Redactor{Detectors: []Detector{DetectEmail}, Mask: MaskKind, MaskFunc: func(kind, value string) string {
+			return "<" + kind + ":" + strconv.Itoa(len(value)) + ">"
+		}}, "mail <email:15>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rd.String(msg); got != tt.want {
+				t.Errorf("String(%q) = %q, want %q", msg, got, tt.want)
+			}
+		})
+	}
+
+	// MaskPartial hides short values entirely
+	rd := &Redactor{Keys: []string{"pin"}, Mask: MaskPartial}
+	if got := rd.Fields([]Field{F("pin", "1234")}); got[0].Value != "****" {
+		t.Errorf("short value masked as %v", got[0].Value)
+	}
+}
+
+func TestRedactorPrinters(t *testing.T) {
+	SetRedactor(NewRedactor())
+	t.Cleanup(func() { SetRedactor(nil) })
+
+	m := map[string]any{"client_secret": "s3cr3t", "contact": "bob@example.com"}
+	if got := MapAsPrettyString(m); strings.Contains(got, "s3cr3t") || strings.Contains(got, "bob@example.com") {
+		t.Errorf("MapAsPrettyString = %s", got)
+	}
+	if got := ValueAsString("token Bearer abcdefghijkl"); got != `"token Bearer [REDACTED]"` {
+		t.Errorf("ValueAsString = %s", got)
+	}
+	if m["client_secret"] != "s3cr3t" {
+		t.Error("the printed map was modified")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	PrintMap(m)
+	os.Stdout = stdout
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(out); !strings.Contains(s, "client_secret: [REDACTED]") || strings.Contains(s, "bob@example.com") {
+		t.Errorf("PrintMap printed %q", s)
+	}
+}
+
+func TestRedactorLogger(t *testing.T) {
+	var out recorder
+	l := testLogger(&out, WithEncoder(LogfmtEncoder{}), WithRedactor(NewRedactor()))
+	l.With("headers", http.Header{"Authorization": {"Bearer abcdefghijkl"}}).Info("login bob@example.com")
+	if s := out.String(); strings.Contains(s, "abcdefghijkl") || strings.Contains(s, "bob@example.com") {
+		t.Errorf("output = %q", s)
+	}
+}
@@ -0,0 +1,367 @@
+package ulog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the UTC timestamp layout used in the names of rotated files
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotateOptions configures a RotatingFile
+type RotateOptions struct {
+	// MaxSize is the size in bytes after which the file is rotated. Zero disables size-based rotation.
+	MaxSize int64
+
+	// Interval rotates the file when a new period starts, e.g. 24 * time.Hour
+	// rotates at midnight UTC. Zero disables time-based rotation.
+	Interval time.Duration
+
+	// MaxBackups is the number of rotated files to keep. Zero keeps all of them.
+	MaxBackups int
+
+	// MaxAge removes rotated files older than this. Zero keeps them regardless of age.
+	MaxAge time.Duration
+
+	// Compress gzips rotated files in the background
+	Compress bool
+
+	// ReopenOnSIGHUP reopens the file when the process receives SIGHUP, so that
+	// an external tool can move it away without losing log lines
+	ReopenOnSIGHUP bool
+
+	// Now is the clock used for rotation and backup names. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// RotatingFile is an io.WriteCloser that writes to a file and rotates it by
+// size and/or time. Rotated files are renamed with a timestamp, e.g.
+// app-2025-05-01T15-04-05.000.log, optionally gzipped, and pruned according to
+// MaxBackups and MaxAge. It is safe for concurrent use and can be used as a
+// Logger output:
+//
+//	file, err := ulog.NewRotatingFile("logs/app.log", ulog.RotateOptions{MaxSize: 10 << 20, MaxBackups: 5})
+//	logger := ulog.NewLogger(true, 1, ulog.WithOutput(file))
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	opts     RotateOptions
+	file     *os.File // nil after a failed rotation or reopen, until a write opens it again
+	size     int64
+	openedAt time.Time
+
+	millMu   sync.Mutex
+	millWG   sync.WaitGroup
+	stopHUP  func()
+	closed   bool
+	millErrs []error
+}
+
+// NewRotatingFile opens (or creates) the file at path for appending, creating
+// missing directories.
+func NewRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	f := &RotatingFile{path: path, opts: opts}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	if opts.ReopenOnSIGHUP {
+		f.stopHUP = notifyHUP(func() { f.Reopen() })
+	}
+	return f, nil
+}
+
+// open opens the file for appending. The caller must hold f.mu.
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = f.opts.Now()
+	if info.Size() > 0 {
+		f.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+// Write implements io.Writer, rotating the file first when the write would
+// exceed MaxSize or a new Interval period has started
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// shouldRotate reports whether writing n more bytes requires a rotation. The caller must hold f.mu.
+func (f *RotatingFile) shouldRotate(n int64) bool {
+	if f.opts.MaxSize > 0 && f.size > 0 && f.size+n > f.opts.MaxSize {
+		return true
+	}
+	if f.opts.Interval > 0 && f.size > 0 {
+		now := f.opts.Now()
+		return !now.Truncate(f.opts.Interval).Equal(f.openedAt.Truncate(f.opts.Interval))
+	}
+	return false
+}
+
+// Rotate closes the current file, renames it with a timestamp and opens a new one
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+// rotate performs the rotation. The caller must hold f.mu. When the rotation
+// fails, the file at the configured path is reopened so that logging recovers;
+// if that fails too, the next write tries to open it again.
+func (f *RotatingFile) rotate() error {
+	if err := f.closeFile(); err != nil {
+		return errors.Join(err, f.open())
+	}
+	backup := f.backupName(f.opts.Now().UTC())
+	if err := os.Rename(f.path, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Join(err, f.open())
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.millWG.Add(1)
+	go func() {
+		defer f.millWG.Done()
+		f.mill(backup)
+	}()
+	return nil
+}
+
+// Reopen closes and reopens the file at the configured path, for use after an
+// external tool such as logrotate has moved it
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return os.ErrClosed
+	}
+	if err := f.closeFile(); err != nil {
+		return errors.Join(err, f.open())
+	}
+	return f.open()
+}
+
+// closeFile closes the current file, if any. The caller must hold f.mu.
+func (f *RotatingFile) closeFile() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// Sync commits the current contents of the file to stable storage
+func (f *RotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return os.ErrClosed
+	}
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+// Close closes the file and waits for background compression and cleanup to finish.
+// It returns the first error encountered by the background work, if any.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.closed = true
+	if f.stopHUP != nil {
+		f.stopHUP()
+	}
+	err := f.closeFile()
+	f.mu.Unlock()
+
+	f.millWG.Wait()
+	if err == nil && len(f.millErrs) > 0 {
+		err = f.millErrs[0]
+	}
+	return err
+}
+
+// backupName returns an unused name for a file rotated at the given time.
+// When several rotations happen within the same millisecond, later ones are
+// named a millisecond apart.
+func (f *RotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := f.nameParts()
+	for {
+		name := filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+		if !fileExists(name) && !fileExists(name+".gz") {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// nameParts splits the path into its directory, the backup name prefix and the extension
+func (f *RotatingFile) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(f.path)
+	base := filepath.Base(f.path)
+	ext = filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// mill compresses a freshly rotated file and removes old backups.
+// Runs are serialised so that concurrent rotations do not race on the directory.
+func (f *RotatingFile) mill(backup string) {
+	f.millMu.Lock()
+	defer f.millMu.Unlock()
+
+	if f.opts.Compress {
+		if err := compressFile(backup); err != nil {
+			f.millErrs = append(f.millErrs, err)
+		}
+	}
+	if err := f.removeOldBackups(); err != nil {
+		f.millErrs = append(f.millErrs, err)
+	}
+}
+
+// backupFile is a rotated file and the time it was rotated at
+type backupFile struct {
+	path string
+	time time.Time
+}
+
+// backups returns the rotated files of f, newest first
+func (f *RotatingFile) backups() ([]backupFile, error) {
+	dir, prefix, ext := f.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []backupFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimPrefix(name, prefix)
+		stamp = strings.TrimSuffix(stamp, ".gz")
+		if !strings.HasSuffix(stamp, ext) {
+			continue
+		}
+		t, err := time.Parse(backupTimeFormat, strings.TrimSuffix(stamp, ext))
+		if err != nil {
+			continue
+		}
+		files = append(files, backupFile{path: filepath.Join(dir, name), time: t})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].time.After(files[j].time) })
+	return files, nil
+}
+
+// removeOldBackups deletes rotated files beyond MaxBackups or older than MaxAge
+func (f *RotatingFile) removeOldBackups() error {
+	if f.opts.MaxBackups <= 0 && f.opts.MaxAge <= 0 {
+		return nil
+	}
+	files, err := f.backups()
+	if err != nil {
+		return err
+	}
+	cutoff := f.opts.Now().Add(-f.opts.MaxAge)
+	var errs []error
+	for i, b := range files {
+		tooMany := f.opts.MaxBackups > 0 && i >= f.opts.MaxBackups
+		tooOld := f.opts.MaxAge > 0 && b.time.Before(cutoff)
+		if tooMany || tooOld {
+			if err := os.Remove(b.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// compressFile gzips the file at path to path+".gz" and removes the original
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	zw.Name = filepath.Base(path)
+	if _, err := io.Copy(zw, src); err != nil {
+		zw.Close()
+		dst.Close()
+		os.Remove(path + ".gz")
+		return fmt.Errorf("ulog: compressing %s: %w", path, err)
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,30 @@
+//go:build !js
+
+package ulog
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyHUP calls fn every time the process receives SIGHUP until the returned stop function is called
+func notifyHUP(fn func()) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				fn()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build js
+
+package ulog
+
+// notifyHUP does nothing on platforms without signals
+func notifyHUP(fn func()) (stop func()) {
+	return func() {}
+}
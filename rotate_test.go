@@ -0,0 +1,277 @@
+package ulog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable clock for RotateOptions.Now
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Date(2025, 5, 1, 10, 30, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// newTestRotatingFile opens app.log in a temporary directory with a fake clock
+func newTestRotatingFile(t *testing.T, opts RotateOptions) (*RotatingFile, *fakeClock) {
+	t.Helper()
+	clock := newFakeClock()
+	opts.Now = clock.Now
+	f, err := NewRotatingFile(filepath.Join(t.TempDir(), "logs", "app.log"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f, clock
+}
+
+// listBackups returns the names of the rotated files next to f, sorted
+func listBackups(t *testing.T, f *RotatingFile) []string {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Dir(f.path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Name() != filepath.Base(f.path) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func writeString(t *testing.T, f *RotatingFile, s string) {
+	t.Helper()
+	if _, err := f.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRotatingFileSize(t *testing.T) {
+	f, clock := newTestRotatingFile(t, RotateOptions{MaxSize: 10})
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		writeString(t, f, line)
+		clock.Advance(time.Second)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"app-2025-05-01T10-30-01.000.log", "app-2025-05-01T10-30-02.000.log"}
+	got := listBackups(t, f)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("backups = %q, want %q", got, want)
+	}
+	dir := filepath.Dir(f.path)
+	for i, content := range []string{"first\n", "second\n"} {
+		if s := readFile(t, filepath.Join(dir, want[i])); s != content {
+			t.Errorf("%s = %q, want %q", want[i], s, content)
+		}
+	}
+	if s := readFile(t, f.path); s != "third\n" {
+		t.Errorf("current file = %q, want %q", s, "third\n")
+	}
+}
+
+func TestRotatingFileInterval(t *testing.T) {
+	f, clock := newTestRotatingFile(t, RotateOptions{Interval: time.Hour})
+	writeString(t, f, "10:30\n")
+	clock.Advance(20 * time.Minute)
+	writeString(t, f, "10:50\n")
+	clock.Advance(20 * time.Minute)
+	writeString(t, f, "11:10\n")
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := listBackups(t, f)
+	if len(got) != 1 || got[0] != "app-2025-05-01T11-10-00.000.log" {
+		t.Fatalf("backups = %q, want one rotated at 11:10", got)
+	}
+	if s := readFile(t, filepath.Join(filepath.Dir(f.path), got[0])); s != "10:30\n10:50\n" {
+		t.Errorf("backup = %q", s)
+	}
+	if s := readFile(t, f.path); s != "11:10\n" {
+		t.Errorf("current file = %q", s)
+	}
+}
+
+func TestRotatingFileMaxBackups(t *testing.T) {
+	f, clock := newTestRotatingFile(t, RotateOptions{MaxBackups: 2})
+	for i := 0; i < 5; i++ {
+		writeString(t, f, "line\n")
+		clock.Advance(time.Minute)
+		if err := f.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"app-2025-05-01T10-34-00.000.log", "app-2025-05-01T10-35-00.000.log"}
+	if got := listBackups(t, f); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("backups = %q, want the newest %q", got, want)
+	}
+}
+
+func TestRotatingFileMaxAge(t *testing.T) {
+	f, clock := newTestRotatingFile(t, RotateOptions{MaxAge: 24 * time.Hour})
+	for i := 0; i < 3; i++ {
+		writeString(t, f, "line\n")
+		if err := f.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(time.Hour)
+	}
+	f.millWG.Wait()
+	if got := listBackups(t, f); len(got) != 3 {
+		t.Fatalf("backups = %q, want all 3 within MaxAge", got)
+	}
+
+	clock.Advance(24 * time.Hour)
+	writeString(t, f, "line\n")
+	if err := f.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"app-2025-05-02T13-30-00.000.log"}
+	if got := listBackups(t, f); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("backups = %q, want %q", got, want)
+	}
+}
+
+func TestRotatingFileCompress(t *testing.T) {
+	f, _ := newTestRotatingFile(t, RotateOptions{Compress: true})
+	writeString(t, f, "compressed line\n")
+	if err := f.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := listBackups(t, f)
+	if len(got) != 1 || got[0] != "app-2025-05-01T10-30-00.000.log.gz" {
+		t.Fatalf("backups = %q, want a single gzipped backup", got)
+	}
+	file, err := os.Open(filepath.Join(filepath.Dir(f.path), got[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "compressed line\n" {
+		t.Errorf("decompressed backup = %q", data)
+	}
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	f, _ := newTestRotatingFile(t, RotateOptions{})
+	writeString(t, f, "before\n")
+
+	// An external tool moves the file away
+	moved := f.path + ".1"
+	if err := os.Rename(f.path, moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	writeString(t, f, "after\n")
+
+	if s := readFile(t, moved); s != "before\n" {
+		t.Errorf("moved file = %q", s)
+	}
+	if s := readFile(t, f.path); s != "after\n" {
+		t.Errorf("reopened file = %q", s)
+	}
+}
+
+func TestRotatingFileRecoversFromFailedOpen(t *testing.T) {
+	f, _ := newTestRotatingFile(t, RotateOptions{})
+	writeString(t, f, "before\n")
+
+	// A directory in place of the file makes opening it fail
+	if err := os.Remove(f.path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(f.path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Reopen(); err == nil {
+		t.Fatal("Reopen succeeded with a directory in place of the file")
+	}
+	if _, err := f.Write([]byte("lost\n")); err == nil {
+		t.Fatal("Write succeeded with a directory in place of the file")
+	}
+
+	if err := os.Remove(f.path); err != nil {
+		t.Fatal(err)
+	}
+	writeString(t, f, "after\n")
+	if s := readFile(t, f.path); s != "after\n" {
+		t.Errorf("file = %q, want %q", s, "after\n")
+	}
+}
+
+func TestRotatingFileLoggerOutput(t *testing.T) {
+	f, _ := newTestRotatingFile(t, RotateOptions{MaxSize: 200, MaxBackups: 3})
+	l := NewLogger(false, 1, WithOutput(f), WithEncoder(JSONEncoder{}))
+	for i := 0; i < 20; i++ {
+		l.Info("message", "FILE")
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := listBackups(t, f); len(got) != 3 {
+		t.Errorf("backups = %q, want 3", got)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(readFile(t, f.path)), "\n") {
+		if !strings.Contains(line, `"msg":"message"`) {
+			t.Errorf("unexpected line %q", line)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package ulog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SamplingOptions configures log sampling. Records are counted per level, tag
+// and message, each key with its own token bucket: a key may log a burst of
+// First records, and earns First records back over every Interval. Once its
+// tokens are used up, only every Thereafter-th record is logged. The number of
+// suppressed records is reported in a summary box, e.g. "suppressed 4,312
+// similar warnings", about once per Interval. Panic and Fatal records are never sampled.
+type SamplingOptions struct {
+	// Interval is the time in which a key earns First records back. Defaults to one second.
+	Interval time.Duration
+
+	// First is the number of records of a key logged per Interval before sampling
+	// starts. Zero uses DefaultSamplingFirst; SamplingNone logs none, so that
+	// Thereafter alone sets the rate, e.g. 1 in 100.
+	First int
+
+	// Thereafter logs every Thereafter-th record beyond First. Zero drops them all.
+	Thereafter int
+}
+
+// DefaultSamplingFirst is the value used when SamplingOptions.First is not set
+const DefaultSamplingFirst = 10
+
+// SamplingNone, as SamplingOptions.First, logs no records before sampling starts
+const SamplingNone = -1
+
+// sampleKey identifies similar records
+type sampleKey struct {
+	level   Level
+	tag     string
+	message string
+}
+
+// sampleCount tracks the records of one key
+type sampleCount struct {
+	tokens     float64   // records the key may log before sampling starts
+	last       time.Time // time of the last record, when the tokens were last refilled
+	over       int       // records beyond the tokens since they ran out
+	suppressed int64     // records dropped since the last summary
+	st         settings  // settings of the last dropped record, used to render the summary
+}
+
+// refill adds the tokens earned since the last record, up to first
+func (c *sampleCount) refill(now time.Time, first int, interval time.Duration) {
+	elapsed := now.Sub(c.last)
+	if elapsed <= 0 {
+		return
+	}
+	c.last = now
+	c.tokens = min(float64(first), c.tokens+float64(first)*float64(elapsed)/float64(interval))
+}
+
+// sampler drops records according to SamplingOptions and reports the dropped ones
+type sampler struct {
+	opts      SamplingOptions // with First resolved to the size of the buckets
+	emit      func(*Record, settings)
+	now       func() time.Time
+	mu        sync.Mutex
+	counts    map[sampleKey]*sampleCount
+	lastSweep time.Time
+	reporting bool // whether a summary is scheduled
+}
+
+// newSampler returns a sampler writing its summaries with emit
+func newSampler(opts SamplingOptions, emit func(*Record, settings)) *sampler {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	switch {
+	case opts.First == 0:
+		opts.First = DefaultSamplingFirst
+	case opts.First < 0:
+		opts.First = 0
+	}
+	return &sampler{opts: opts, emit: emit, now: time.Now, counts: make(map[sampleKey]*sampleCount)}
+}
+
+// allow reports whether the record should be logged, counting it otherwise
+func (s *sampler) allow(r *Record, st settings) bool {
+	if r.Level >= LevelPanic {
+		return true
+	}
+	now := s.now()
+	key := sampleKey{level: r.Level, tag: r.Tag, message: r.Message}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	c := s.counts[key]
+	if c == nil {
+		c = &sampleCount{tokens: float64(s.opts.First), last: now}
+		s.counts[key] = c
+	}
+	c.refill(now, s.opts.First, s.opts.Interval)
+	if c.tokens >= 1 {
+		c.tokens--
+		c.over = 0
+		return true
+	}
+	c.over++
+	if s.opts.Thereafter > 0 && c.over%s.opts.Thereafter == 0 {
+		return true
+	}
+
+	c.suppressed++
+	c.st = st
+	if !s.reporting {
+		s.reporting = true
+		time.AfterFunc(s.opts.Interval, s.report)
+	}
+	return false
+}
+
+// sweep forgets keys that have been idle for an interval, and so have a full
+// bucket again, and that have nothing to report, so that the counts do not grow
+// with every distinct message. The caller must hold s.mu.
+func (s *sampler) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.opts.Interval {
+		return
+	}
+	s.lastSweep = now
+	for key, c := range s.counts {
+		if c.suppressed == 0 && now.Sub(c.last) >= s.opts.Interval {
+			delete(s.counts, key)
+		}
+	}
+}
+
+// report writes a summary for every key with suppressed records. It runs about
+// once per interval and when the logger is flushed, closed or exits.
+func (s *sampler) report() {
+	type summary struct {
+		key sampleKey
+		n   int64
+		st  settings
+	}
+	s.mu.Lock()
+	var summaries []summary
+	for key, c := range s.counts {
+		if c.suppressed > 0 {
+			summaries = append(summaries, summary{key, c.suppressed, c.st})
+			c.suppressed = 0
+		}
+	}
+	s.reporting = false
+	s.mu.Unlock()
+
+	now := s.now()
+	for _, sum := range summaries {
+		s.emit(&Record{
+			Time:    now,
+			Level:   sum.key.level,
+			Tag:     sum.key.tag,
+			Message: fmt.Sprintf("suppressed %s similar %s", ReadableCount(sum.n), levelNoun(sum.key.level, sum.n)),
+			Fields:  []Field{F("message", sum.key.message)},
+		}, sum.st)
+	}
+}
+
+// levelNoun names records of a level in a summary, e.g. "warnings"
+func levelNoun(level Level, n int64) string {
+	noun := level.String() + " message"
+	switch level {
+	case LevelWarning:
+		noun = "warning"
+	case LevelError:
+		noun = "error"
+	}
+	if n != 1 {
+		noun += "s"
+	}
+	return noun
+}
+
+// SetSampling limits repeated records of the logger tree, the logger with its
+// parents and all their children, as described by SamplingOptions. Passing nil
+// turns sampling off.
+func (l *Logger) SetSampling(opts *SamplingOptions) {
+	var s *sampler
+	if opts != nil {
+		s = newSampler(*opts, l.core.emit)
+	}
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	l.core.sampler = s
+}
+
+// SetSampling sets the sampling of the default logger
+func SetSampling(opts *SamplingOptions) {
+	DefaultLogger.SetSampling(opts)
+}
@@ -0,0 +1,141 @@
+package ulog
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSamplingZeroOptions(t *testing.T) {
+	var out recorder
+	l := testLogger(&out, WithEncoder(LogfmtEncoder{}), WithSampling(SamplingOptions{}))
+	for i := 0; i < 20; i++ {
+		l.Error("boom")
+	}
+	if n := strings.Count(out.String(), "msg=boom"); n != DefaultSamplingFirst {
+		t.Errorf("got %d records, want the first %d", n, DefaultSamplingFirst)
+	}
+}
+
+func TestSamplingSummaryOnFlush(t *testing.T) {
+	var out recorder
+	l := testLogger(&out, WithEncoder(LogfmtEncoder{}),
+		WithSampling(SamplingOptions{Interval: time.Hour, First: 2, Thereafter: 10}))
+	for i := 0; i < 1234; i++ {
+		l.Warning("retrying", "HTTP")
+	}
+	if err := l.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	s := out.String()
+	// 2 first records, then every 10th of the remaining 1232
+	if n := strings.Count(s, "msg=retrying"); n != 2+123 {
+		t.Errorf("got %d records, want %d", n, 2+123)
+	}
+	if !strings.Contains(s, `msg="suppressed 1,109 similar warnings"`) {
+		t.Errorf("summary missing from output:\n%s", s[strings.LastIndex(strings.TrimSuffix(s, "\n"), "\n")+1:])
+	}
+}
+
+func TestSamplingSummaryOnFatal(t *testing.T) {
+	var out recorder
+	l := testLogger(&out, WithEncoder(LogfmtEncoder{}), WithExitFunc(func(int) {}),
+		WithSampling(SamplingOptions{Interval: time.Hour, First: 1}))
+	for i := 0; i < 5; i++ {
+		l.Error("boom")
+	}
+	l.Fatal("giving up")
+	if s := out.String(); !strings.Contains(s, `msg="suppressed 4 similar errors"`) {
+		t.Errorf("summary missing from output:\n%s", s)
+	}
+}
+
+// sampledLogger returns a logfmt logger whose sampler uses clock
+func sampledLogger(out *recorder, clock *fakeClock, opts SamplingOptions) *Logger {
+	l := testLogger(out, WithEncoder(LogfmtEncoder{}), WithSampling(opts))
+	l.core.sampler.now = clock.Now
+	return l
+}
+
+func TestSamplingTokenBucket(t *testing.T) {
+	var out recorder
+	clock := newFakeClock()
+	l := sampledLogger(&out, clock, SamplingOptions{Interval: time.Second, First: 4})
+	logged := func() int { return strings.Count(out.String(), "msg=boom") }
+
+	for i := 0; i < 10; i++ {
+		l.Error("boom")
+	}
+	if n := logged(); n != 4 {
+		t.Fatalf("got %d records from a full bucket, want 4", n)
+	}
+
+	// Half an interval earns half of the tokens back
+	clock.Advance(500 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		l.Error("boom")
+	}
+	if n := logged(); n != 4+2 {
+		t.Fatalf("got %d records, want %d", n, 4+2)
+	}
+
+	// The bucket never holds more than First tokens
+	clock.Advance(time.Minute)
+	for i := 0; i < 10; i++ {
+		l.Error("boom")
+	}
+	if n := logged(); n != 4+2+4 {
+		t.Fatalf("got %d records, want %d", n, 4+2+4)
+	}
+
+	// A steady rate below First per Interval is never sampled
+	for i := 0; i < 20; i++ {
+		clock.Advance(250 * time.Millisecond)
+		l.Error("boom")
+	}
+	if n := logged(); n != 4+2+4+20 {
+		t.Fatalf("got %d records, want %d", n, 4+2+4+20)
+	}
+}
+
+func TestSamplingKeys(t *testing.T) {
+	var out recorder
+	l := sampledLogger(&out, newFakeClock(), SamplingOptions{First: 1})
+	for i := 0; i < 3; i++ {
+		l.Error("boom")
+		l.Error("boom", "DB")
+		l.Warning("boom")
+		l.Error("bang")
+	}
+	if n := strings.Count(out.String(), "\n"); n != 4 {
+		t.Errorf("got %d records, want one per key:\n%s", n, out.String())
+	}
+}
+
+func TestSamplingOneInN(t *testing.T) {
+	var out recorder
+	l := sampledLogger(&out, newFakeClock(), SamplingOptions{First: SamplingNone, Thereafter: 100})
+	for i := 0; i < 1000; i++ {
+		l.Info("cache miss")
+	}
+	if n := strings.Count(out.String(), `msg="cache miss"`); n != 10 {
+		t.Errorf("got %d records, want 1 in 100", n)
+	}
+}
+
+func TestSamplingSweep(t *testing.T) {
+	var out recorder
+	clock := newFakeClock()
+	l := sampledLogger(&out, clock, SamplingOptions{Interval: time.Second, First: 1})
+	for i := 0; i < 100; i++ {
+		l.Info("request " + strconv.Itoa(i))
+	}
+	clock.Advance(2 * time.Second)
+	l.Info("later")
+	if n := len(l.core.sampler.counts); n != 1 {
+		t.Errorf("sampler keeps %d keys after they went idle, want 1", n)
+	}
+}
@@ -0,0 +1,295 @@
+package ulog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Sink is an output destination of a Logger with its own encoder and filters.
+// A Logger writes every record to each of its sinks that accepts it, so the same
+// call can draw a box on the terminal, append JSON to a file and send errors to
+// an alerts file:
+//
+//	logger.SetSinks(
+//	    &ulog.Sink{Writer: os.Stdout},
+//	    &ulog.Sink{Writer: logFile, Encoder: ulog.JSONEncoder{}},
+//	    &ulog.Sink{Writer: alertsFile, Encoder: ulog.JSONEncoder{}, Level: ulog.LevelError},
+//	)
+//
+// Sinks must not be copied after first use.
+type Sink struct {
+	// Writer receives the encoded records
+	Writer io.Writer
+
+	// ErrorWriter, when set, receives Warning and Error records instead of Writer
+	ErrorWriter io.Writer
+
+	// Encoder renders records for this sink. Nil uses the logger's encoder.
+	Encoder Encoder
+
+	// Level is the minimum level of the records written to this sink
+	Level Level
+
+	// Tags limits the sink to records with one of these tags (case-insensitive).
+	// A tag also matches its nested tags, e.g. "api" matches "api.db". Empty accepts all tags.
+	Tags []string
+
+	// ColorProfile overrides the color profile detected for the sink's writers
+	ColorProfile ColorProfile
+
+	mu         sync.Mutex
+	outProfile ColorProfile
+	errProfile ColorProfile
+}
+
+// SinkError is passed to the error handler when writing to a sink fails
+type SinkError struct {
+	Sink *Sink
+	Err  error
+}
+
+// Error implements error
+func (e *SinkError) Error() string {
+	return "ulog: writing to sink: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *SinkError) Unwrap() error {
+	return e.Err
+}
+
+// accepts reports whether the sink writes records of the given level and tag
+func (s *Sink) accepts(level Level, tag string) bool {
+	if level < s.Level {
+		return false
+	}
+	if len(s.Tags) == 0 {
+		return true
+	}
+	for _, t := range s.Tags {
+		if strings.EqualFold(tag, t) || (len(tag) > len(t) && tag[len(t)] == '.' && strings.EqualFold(tag[:len(t)], t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// target returns the writer used for records of the given level and its color
+// profile, detecting the profile on first use. The caller must hold s.mu.
+func (s *Sink) target(level Level) (io.Writer, ColorProfile) {
+	if level >= LevelWarning && s.ErrorWriter != nil {
+		if s.errProfile == ProfileAuto {
+			s.errProfile = DetectColorProfile(s.ErrorWriter)
+		}
+		return s.ErrorWriter, s.errProfile
+	}
+	if s.outProfile == ProfileAuto {
+		s.outProfile = DetectColorProfile(s.Writer)
+	}
+	return s.Writer, s.outProfile
+}
+
+// write encodes the record for the sink and writes it with a single Write call
+// under the sink's lock, so that entries from concurrent calls never interleave
+func (s *Sink) write(r *Record, st settings) error {
+	s.mu.Lock()
+	w, profile := s.target(r.Level)
+	s.mu.Unlock()
+	if w == nil {
+		return nil
+	}
+	if st.colorProfile != ProfileAuto {
+		profile = st.colorProfile
+	}
+	if s.ColorProfile != ProfileAuto {
+		profile = s.ColorProfile
+	}
+
+	enc := s.Encoder
+	if enc == nil {
+		enc = st.encoder
+	}
+	rec := *r
+	rec.hints = &renderHints{st: st, w: w, profile: profile}
+	var buf bytes.Buffer
+	if err := enc.Encode(&buf, &rec); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// core holds the sinks and error handler of a Logger. It is shared with child
+// loggers so that they write to the same destinations.
+type core struct {
+	mu           sync.RWMutex
+	console      *Sink
+	sinks        []*Sink
+	errorHandler func(error)
+	async        *asyncQueue // nil when records are written synchronously
+	asyncMu      sync.Mutex  // serialises replacing and closing async
+	dropped      atomic.Uint64
+	exit         func(int)  // nil means os.Exit
+	sampler      *sampler   // nil when sampling is off
+	collapser    *collapser // nil when collapsing is off
+}
+
+// newCore returns a core writing to a console sink on os.Stdout
+func newCore() *core {
+	console := &Sink{Writer: os.Stdout}
+	return &core{console: console, sinks: []*Sink{console}}
+}
+
+// write runs the Before hooks, then collapses the record with identical previous
+// ones when collapsing is on, and sends it on otherwise
+func (c *core) write(r *Record, st settings) {
+	if !c.runBefore(r, st.hooks) {
+		return
+	}
+	c.mu.RLock()
+	col := c.collapser
+	c.mu.RUnlock()
+	if col != nil {
+		col.add(r, st)
+		return
+	}
+	c.sample(r, st)
+}
+
+// sample sends the record on unless the sampler drops it
+func (c *core) sample(r *Record, st settings) {
+	c.mu.RLock()
+	s := c.sampler
+	c.mu.RUnlock()
+	if s != nil && !s.allow(r, st) {
+		return
+	}
+	c.emit(r, st)
+}
+
+// emit redacts the record and sends it to the asynchronous queue, or to the sinks
+// directly when the logger is synchronous. When the queue is being closed, the
+// record is written once the queued ones have been.
+func (c *core) emit(r *Record, st settings) {
+	if st.redactor != nil {
+		r = st.redactor.redactRecord(r)
+	}
+	c.mu.RLock()
+	q := c.async
+	c.mu.RUnlock()
+	if q != nil {
+		if q.push(asyncEntry{r: r, st: st}) {
+			return
+		}
+		<-q.done
+	}
+	c.dispatch(r, st)
+}
+
+// flushHeld writes the record held back by the collapser and the pending
+// summaries of the sampler, so that they are not lost when the program exits
+func (c *core) flushHeld() {
+	c.mu.RLock()
+	col, s := c.collapser, c.sampler
+	c.mu.RUnlock()
+	if col != nil {
+		col.flush()
+	}
+	if s != nil {
+		s.report()
+	}
+}
+
+// snapshot returns the current sinks
+func (c *core) snapshot() []*Sink {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sinks
+}
+
+// handleError reports a write error to the error handler, or to os.Stderr by default
+func (c *core) handleError(err error) {
+	c.mu.RLock()
+	handler := c.errorHandler
+	c.mu.RUnlock()
+	if handler != nil {
+		handler(err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, err)
+}
+
+// dispatch writes the record to every sink that accepts it and runs the After
+// hooks. A failing sink does not prevent the others from being written; its
+// error goes to the error handler.
+func (c *core) dispatch(r *Record, st settings) {
+	for _, s := range c.snapshot() {
+		if !s.accepts(r.Level, r.Tag) {
+			continue
+		}
+		if err := s.write(r, st); err != nil {
+			c.handleError(&SinkError{Sink: s, Err: err})
+		}
+	}
+	c.runAfter(r, st.hooks)
+}
+
+// consoleSink returns the console sink configured by SetOutput and
+// SetErrorOutput, adding it back in front of the sinks if it was removed
+// by SetSinks. The caller must hold c.mu.
+func (c *core) consoleSink() *Sink {
+	if c.console == nil {
+		c.console = &Sink{Writer: os.Stdout}
+		c.sinks = append([]*Sink{c.console}, c.sinks...)
+	}
+	return c.console
+}
+
+// AddSink adds an output destination to the logger tree: the sink receives the
+// records of the logger, its parents and all their children
+func (l *Logger) AddSink(s *Sink) {
+	if s == nil {
+		return
+	}
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	l.core.sinks = append(l.core.sinks[:len(l.core.sinks):len(l.core.sinks)], s)
+}
+
+// SetSinks replaces all output destinations of the logger tree, including the
+// default console output. Called on a child, it also replaces the sinks of its
+// parent and siblings.
+func (l *Logger) SetSinks(sinks ...*Sink) {
+	list := make([]*Sink, 0, len(sinks))
+	for _, s := range sinks {
+		if s != nil {
+			list = append(list, s)
+		}
+	}
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	l.core.console = nil
+	l.core.sinks = list
+}
+
+// Sinks returns the output destinations of the logger
+func (l *Logger) Sinks() []*Sink {
+	sinks := l.core.snapshot()
+	return append([]*Sink(nil), sinks...)
+}
+
+// SetErrorHandler sets the function called when writing to a sink of the logger
+// tree fails. By default errors are printed to os.Stderr.
+func (l *Logger) SetErrorHandler(handler func(error)) {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	l.core.errorHandler = handler
+}
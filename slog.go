@@ -0,0 +1,200 @@
+package ulog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogHandlerOptions configures a handler created by NewSlogHandler
+type SlogHandlerOptions struct {
+	// Level is an additional minimum slog level. The logger's own level is always respected.
+	Level slog.Leveler
+
+	// TagKey is the name of a top-level attribute whose value is shown as the box tag
+	// instead of as a field, e.g. "component". Disabled when empty.
+	TagKey string
+}
+
+// SlogHandler is a slog.Handler that renders records with a ulog Logger.
+// Attributes become structured fields inside the box and groups become nested fields.
+type SlogHandler struct {
+	l      *Logger
+	opts   SlogHandlerOptions
+	tag    string
+	fields []Field
+	groups []string
+}
+
+// NewSlogHandler creates a slog.Handler backed by the given logger, so that
+// slog output uses the logger's box renderer, writers, level and timestamp/padding settings.
+// A nil logger uses DefaultLogger and nil options use the defaults.
+//
+// Example:
+//
+//	slog.SetDefault(slog.New(ulog.NewSlogHandler(logger, nil)))
+//	slog.Warn("disk almost full", "free", "2GB")
+func NewSlogHandler(l *Logger, opts *SlogHandlerOptions) *SlogHandler {
+	if l == nil {
+		l = DefaultLogger
+	}
+	h := &SlogHandler{l: l}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+// levelFromSlog maps a slog level to the closest ulog level
+func levelFromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarning
+	default:
+		return LevelError
+	}
+}
+
+// Enabled implements slog.Handler
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	if h.opts.Level != nil && level < h.opts.Level.Level() {
+		return false
+	}
+	return h.l.Enabled(levelFromSlog(level))
+}
+
+// Handle implements slog.Handler
+func (h *SlogHandler) Handle(ctx context.Context, sr slog.Record) error {
+	r := &Record{
+		Time:    sr.Time,
+		Level:   levelFromSlog(sr.Level),
+		Tag:     h.tag,
+		Message: sr.Message,
+		Caller:  callerFromPC(sr.PC, h.l.settings().caller),
+	}
+
+	attrs := make([]slog.Attr, 0, sr.NumAttrs())
+	sr.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	fields := h.fields
+	if len(h.groups) == 0 {
+		attrs = h.takeTag(attrs, &r.Tag)
+	}
+	fields = insertFields(fields, h.groups, attrsToFields(attrs))
+	r.Fields = appendFields(h.l.fields, fields...)
+	if extracted := h.l.contextFields(ctx); len(extracted) > 0 {
+		r.Fields = appendFields(r.Fields, extracted...)
+	}
+
+	h.l.write(r)
+	return nil
+}
+
+// WithAttrs implements slog.Handler
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	child := *h
+	if len(h.groups) == 0 {
+		attrs = child.takeTag(attrs, &child.tag)
+	}
+	child.fields = insertFields(h.fields, h.groups, attrsToFields(attrs))
+	return &child
+}
+
+// WithGroup implements slog.Handler
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	child := *h
+	child.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &child
+}
+
+// takeTag removes the TagKey attribute from attrs and stores its value in tag
+func (h *SlogHandler) takeTag(attrs []slog.Attr, tag *string) []slog.Attr {
+	if h.opts.TagKey == "" {
+		return attrs
+	}
+	result := attrs[:0:0]
+	for _, a := range attrs {
+		if a.Key == h.opts.TagKey {
+			*tag = a.Value.Resolve().String()
+			continue
+		}
+		result = append(result, a)
+	}
+	return result
+}
+
+// attrsToFields converts slog attributes to fields, turning groups into nested maps
+func attrsToFields(attrs []slog.Attr) []Field {
+	fields := make([]Field, 0, len(attrs))
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() == slog.KindGroup {
+			nested := attrsToFields(a.Value.Group())
+			if len(nested) == 0 {
+				continue
+			}
+			if a.Key == "" {
+				// Groups with an empty key are inlined
+				fields = append(fields, nested...)
+				continue
+			}
+			m := make(map[string]any, len(nested))
+			for _, f := range nested {
+				m[f.Key] = f.Value
+			}
+			fields = append(fields, Field{Key: a.Key, Value: m})
+			continue
+		}
+		if a.Key == "" {
+			continue
+		}
+		fields = append(fields, Field{Key: a.Key, Value: a.Value.Any()})
+	}
+	return fields
+}
+
+// insertFields returns a copy of fields with add placed inside the nested group path
+func insertFields(fields []Field, groups []string, add []Field) []Field {
+	if len(add) == 0 {
+		return fields
+	}
+	if len(groups) == 0 {
+		return appendFields(fields, add...)
+	}
+	result := appendFields(fields)
+	for i := len(result) - 1; i >= 0; i-- {
+		if m, ok := result[i].Value.(map[string]any); ok && result[i].Key == groups[0] {
+			result[i].Value = mergeGroup(m, groups[1:], add)
+			return result
+		}
+	}
+	return append(result, Field{Key: groups[0], Value: mergeGroup(nil, groups[1:], add)})
+}
+
+// mergeGroup returns a copy of m with add placed inside the nested group path
+func mergeGroup(m map[string]any, groups []string, add []Field) map[string]any {
+	result := make(map[string]any, len(m)+len(add))
+	for k, v := range m {
+		result[k] = v
+	}
+	if len(groups) == 0 {
+		for _, f := range add {
+			result[f.Key] = f.Value
+		}
+		return result
+	}
+	inner, _ := result[groups[0]].(map[string]any)
+	result[groups[0]] = mergeGroup(inner, groups[1:], add)
+	return result
+}
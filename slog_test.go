@@ -0,0 +1,162 @@
+package ulog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+)
+
+func TestSlogHandlerConformance(t *testing.T) {
+	var out recorder
+	l := testLogger(&out, WithEncoder(JSONEncoder{}))
+	results := func() []map[string]any {
+		var ms []map[string]any
+		for _, line := range bytes.Split(bytes.TrimSpace([]byte(out.String())), []byte("\n")) {
+			var m map[string]any
+			if err := json.Unmarshal(line, &m); err != nil {
+				t.Fatal(err)
+			}
+			ms = append(ms, m)
+		}
+		return ms
+	}
+	if err := slogtest.TestHandler(NewSlogHandler(l, nil), results); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSlogHandlerBox(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	var out recorder
+	l := testLogger(&out)
+	logger := slog.New(NewSlogHandler(l, &SlogHandlerOptions{TagKey: "component"}))
+	logger.With("component", "db", "user", 42).WithGroup("req").Warn("slow query", "ms", 1200)
+
+	boxes := splitBoxes(t, out.String())
+	if len(boxes) != 1 {
+		t.Fatalf("got %d boxes, want 1:\n%s", len(boxes), out.String())
+	}
+	checkBox(t, boxes[0])
+	box := strings.Join(boxes[0], "\n")
+	for _, want := range []string{"db", "slow query", "user", "42", "req", "ms", "1200"} {
+		if !strings.Contains(box, want) {
+			t.Errorf("box does not contain %q:\n%s", want, box)
+		}
+	}
+	if strings.Contains(box, "component") {
+		t.Errorf("tag attribute rendered as a field:\n%s", box)
+	}
+}
+
+func TestSlogHandlerGroups(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(*slog.Logger)
+		want string
+	}{
+		{"attrs", func(l *slog.Logger) { l.Info("m", "a", 1) }, "msg=m a=1"},
+		{"with group", func(l *slog.Logger) {
+			l.With("a", 1).WithGroup("g").With("b", 2).WithGroup("h").Info("m", "c", 3)
+		}, "msg=m a=1 g.b=2 g.h.c=3"},
+		{"group attr", func(l *slog.Logger) {
+			l.WithGroup("g").Info("m", slog.Group("i", "d", 4), slog.Group("empty"))
+		}, "msg=m g.i.d=4"},
+		{"inlined group", func(l *slog.Logger) { l.Info("m", slog.Group("", "a", 1)) }, "msg=m a=1"},
+		{"group without attrs", func(l *slog.Logger) { l.WithGroup("g").Info("m") }, "msg=m"},
+		{"merged group", func(l *slog.Logger) {
+			l.WithGroup("g").With("a", 1).With("b", 2).Info("m", "c", 3)
+		}, "msg=m g.a=1 g.b=2 g.c=3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out recorder
+			tt.log(slog.New(NewSlogHandler(testLogger(&out, WithEncoder(LogfmtEncoder{})), nil)))
+			if s := strings.TrimSpace(out.String()); !strings.HasSuffix(s, tt.want) {
+				t.Errorf("output = %q, want it to end with %q", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlogHandlerGroupsAreCopied(t *testing.T) {
+	var out recorder
+	base := slog.New(NewSlogHandler(testLogger(&out, WithEncoder(LogfmtEncoder{})), nil)).WithGroup("g").With("a", 1)
+	base.With("b", 2).Info("child")
+	base.Info("base")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "g.a=1 g.b=2") || !strings.HasSuffix(lines[1], "msg=base g.a=1") {
+		t.Errorf("output = %q", lines)
+	}
+}
+
+func TestSlogHandlerTagKey(t *testing.T) {
+	var out recorder
+	h := NewSlogHandler(testLogger(&out, WithEncoder(LogfmtEncoder{})), &SlogHandlerOptions{TagKey: "component"})
+	logger := slog.New(h)
+	logger.With("component", "db").Info("from with")
+	logger.Info("from attrs", "component", "api")
+	logger.WithGroup("g").Info("in group", "component", "cache")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := []string{"level=info tag=db msg=\"from with\"", "level=info tag=api msg=\"from attrs\"", "level=info msg=\"in group\" g.component=cache"}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d: %q", len(lines), len(want), lines)
+	}
+	for i := range want {
+		if !strings.HasSuffix(lines[i], want[i]) {
+			t.Errorf("line %d = %q, want it to end with %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestSlogLevels(t *testing.T) {
+	tests := []struct {
+		in   slog.Level
+		want Level
+	}{
+		{slog.LevelDebug - 4, LevelDebug},
+		{slog.LevelDebug, LevelDebug},
+		{slog.LevelInfo, LevelInfo},
+		{slog.LevelInfo + 2, LevelInfo},
+		{slog.LevelWarn, LevelWarning},
+		{slog.LevelError - 1, LevelWarning},
+		{slog.LevelError, LevelError},
+		{slog.LevelError + 4, LevelError},
+	}
+	for _, tt := range tests {
+		if got := levelFromSlog(tt.in); got != tt.want {
+			t.Errorf("levelFromSlog(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSlogHandlerEnabled(t *testing.T) {
+	var out recorder
+	l := testLogger(&out, WithEncoder(LogfmtEncoder{}))
+	h := NewSlogHandler(l, nil)
+	ctx := context.Background()
+
+	l.SetLevel(LevelWarning)
+	if h.Enabled(ctx, slog.LevelInfo) || !h.Enabled(ctx, slog.LevelWarn) {
+		t.Error("Enabled does not follow the logger level")
+	}
+	l.SetLevel(LevelDebug)
+	if !h.Enabled(ctx, slog.LevelDebug) {
+		t.Error("Enabled does not follow a changed logger level")
+	}
+
+	strict := NewSlogHandler(l, &SlogHandlerOptions{Level: slog.LevelError})
+	if strict.Enabled(ctx, slog.LevelWarn) || !strict.Enabled(ctx, slog.LevelError) {
+		t.Error("Enabled ignores SlogHandlerOptions.Level")
+	}
+
+	slog.New(strict).Warn("hidden")
+	slog.New(h).Debug("shown")
+	if s := out.String(); strings.Contains(s, "hidden") || !strings.Contains(s, "msg=shown") {
+		t.Errorf("output = %q", s)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !zos && !windows
+
+package ulog
+
+import "io"
+
+// terminalWidth always returns 0 on platforms without terminal size detection
+func terminalWidth(w io.Writer) int {
+	return 0
+}
+
+// enableVirtualTerminal reports whether the terminal w writes to understands ANSI escape sequences
+func enableVirtualTerminal(w io.Writer) bool {
+	return true
+}
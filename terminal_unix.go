@@ -0,0 +1,30 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos
+
+package ulog
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalWidth returns the number of columns of the terminal w writes to,
+// or 0 when w is not a terminal
+func terminalWidth(w io.Writer) int {
+	f, ok := w.(*os.File)
+	if !ok {
+		return 0
+	}
+	ws, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0
+	}
+	return int(ws.Col)
+}
+
+// enableVirtualTerminal reports whether the terminal w writes to understands
+// ANSI escape sequences, which is always the case on Unix systems
+func enableVirtualTerminal(w io.Writer) bool {
+	return true
+}
@@ -0,0 +1,282 @@
+package ulog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// colorKind tells how the value of a Color is interpreted
+type colorKind uint8
+
+const (
+	colorDefault colorKind = iota
+	colorBasic
+	color256
+	colorRGB
+)
+
+// Color is a terminal color: the terminal's default color, one of the 16 basic
+// ANSI colors, an index in the 256-color palette or a 24-bit RGB value.
+// The zero value is the terminal's default color.
+type Color struct {
+	kind    colorKind
+	index   uint8
+	r, g, b uint8
+}
+
+// The 16 basic ANSI colors. Their exact shade depends on the terminal's palette.
+var (
+	ColorDefault       = Color{}
+	ColorBlack         = basicColor(0)
+	ColorRed           = basicColor(1)
+	ColorGreen         = basicColor(2)
+	ColorYellow        = basicColor(3)
+	ColorBlue          = basicColor(4)
+	ColorMagenta       = basicColor(5)
+	ColorCyan          = basicColor(6)
+	ColorWhite         = basicColor(7)
+	ColorBrightBlack   = basicColor(8)
+	ColorBrightRed     = basicColor(9)
+	ColorBrightGreen   = basicColor(10)
+	ColorBrightYellow  = basicColor(11)
+	ColorBrightBlue    = basicColor(12)
+	ColorBrightMagenta = basicColor(13)
+	ColorBrightCyan    = basicColor(14)
+	ColorBrightWhite   = basicColor(15)
+)
+
+func basicColor(index uint8) Color {
+	return Color{kind: colorBasic, index: index}
+}
+
+// Color256 returns the color with the given index in the 256-color palette
+func Color256(index uint8) Color {
+	return Color{kind: color256, index: index}
+}
+
+// ColorRGB returns a 24-bit true color
+func ColorRGB(r, g, b uint8) Color {
+	return Color{kind: colorRGB, r: r, g: g, b: b}
+}
+
+// ColorHex parses a true color written as "#rrggbb" or "#rgb"
+func ColorHex(hex string) (Color, error) {
+	s := strings.TrimPrefix(hex, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return Color{}, fmt.Errorf("ulog: invalid hex color %q", hex)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("ulog: invalid hex color %q", hex)
+	}
+	return ColorRGB(uint8(v>>16), uint8(v>>8), uint8(v)), nil
+}
+
+// sgr returns the SGR parameters selecting the color as foreground or background
+func (c Color) sgr(background bool) []string {
+	offset := 0
+	if background {
+		offset = 10
+	}
+	switch c.kind {
+	case colorBasic:
+		if c.index >= 8 {
+			return []string{strconv.Itoa(90 + offset + int(c.index) - 8)}
+		}
+		return []string{strconv.Itoa(30 + offset + int(c.index))}
+	case color256:
+		return []string{strconv.Itoa(38 + offset), "5", strconv.Itoa(int(c.index))}
+	case colorRGB:
+		return []string{strconv.Itoa(38 + offset), "2", strconv.Itoa(int(c.r)), strconv.Itoa(int(c.g)), strconv.Itoa(int(c.b))}
+	}
+	return nil
+}
+
+// Style is the color and text attributes of one part of a box
+type Style struct {
+	Color      Color
+	Background Color
+	Bold       bool
+	Faint      bool
+	Italic     bool
+	Underline  bool
+}
+
+// sgr returns the SGR parameters of the style
+func (s Style) sgr() []string {
+	var params []string
+	if s.Bold {
+		params = append(params, "1")
+	}
+	if s.Faint {
+		params = append(params, "2")
+	}
+	if s.Italic {
+		params = append(params, "3")
+	}
+	if s.Underline {
+		params = append(params, "4")
+	}
+	params = append(params, s.Color.sgr(false)...)
+	return append(params, s.Background.sgr(true)...)
+}
+
+// paint applies the style to text. Text is returned unchanged for an empty style.
+func (s Style) paint(text string) string {
+	if text == "" {
+		return text
+	}
+	params := s.sgr()
+	if len(params) == 0 {
+		return text
+	}
+	return "\x1b[" + strings.Join(params, ";") + "m" + text + "\x1b[0m"
+}
+
+// LevelTheme styles the parts of the boxes of one level
+type LevelTheme struct {
+	Border    Style
+	Tag       Style
+	Timestamp Style
+	Body      Style
+}
+
+// Theme styles boxes for every level. Levels missing from Levels use the
+// LevelInfo entry, or no styling at all. LevelPanic falls back to LevelFatal.
+type Theme struct {
+	Name   string
+	Levels map[Level]LevelTheme
+}
+
+// forLevel returns the styles of the given level
+func (t Theme) forLevel(level Level) LevelTheme {
+	if lt, ok := t.Levels[level]; ok {
+		return lt
+	}
+	if lt, ok := t.Levels[LevelFatal]; ok && level == LevelPanic {
+		return lt
+	}
+	return t.Levels[LevelInfo]
+}
+
+// clone returns a copy of the theme that does not share its Levels map
+func (t Theme) clone() Theme {
+	levels := make(map[Level]LevelTheme, len(t.Levels))
+	for level, lt := range t.Levels {
+		levels[level] = lt
+	}
+	t.Levels = levels
+	return t
+}
+
+// solidTheme styles the whole box of a level with one color and a bold tag
+func solidTheme(c Color) LevelTheme {
+	s := Style{Color: c}
+	return LevelTheme{Border: s, Tag: Style{Color: c, Bold: true}, Timestamp: s, Body: s}
+}
+
+// Built-in themes
+var (
+	// ThemeDark is the default theme, designed for terminals with a dark background
+	ThemeDark = Theme{
+		Name: "dark",
+		Levels: map[Level]LevelTheme{
+			LevelDebug:   solidTheme(ColorBlue),
+			LevelInfo:    solidTheme(ColorDefault),
+			LevelSuccess: solidTheme(ColorGreen),
+			LevelOngoing: solidTheme(Color256(214)),
+			LevelWarning: solidTheme(ColorYellow),
+			LevelError:   solidTheme(ColorRed),
+			LevelFatal: {
+				Border:    Style{Color: ColorRed, Bold: true},
+				Tag:       Style{Color: ColorBrightWhite, Background: ColorRed, Bold: true},
+				Timestamp: Style{Color: ColorRed},
+				Body:      Style{Color: ColorRed, Bold: true},
+			},
+		},
+	}
+
+	// ThemeLight uses darker shades that stay readable on a light background
+	ThemeLight = Theme{
+		Name: "light",
+		Levels: map[Level]LevelTheme{
+			LevelDebug:   lightTheme(Color256(25)),
+			LevelInfo:    lightTheme(ColorDefault),
+			LevelSuccess: lightTheme(Color256(28)),
+			LevelOngoing: lightTheme(Color256(166)),
+			LevelWarning: lightTheme(Color256(136)),
+			LevelError:   lightTheme(Color256(160)),
+			LevelFatal: {
+				Border:    Style{Color: Color256(124), Bold: true},
+				Tag:       Style{Color: ColorWhite, Background: Color256(124), Bold: true},
+				Timestamp: Style{Color: Color256(124), Faint: true},
+				Body:      Style{Color: Color256(124), Bold: true},
+			},
+		},
+	}
+
+	// ThemeHighContrast uses bright, bold colors for maximum legibility
+	ThemeHighContrast = Theme{
+		Name: "high-contrast",
+		Levels: map[Level]LevelTheme{
+			LevelDebug:   boldTheme(ColorBrightCyan),
+			LevelInfo:    boldTheme(ColorBrightWhite),
+			LevelSuccess: boldTheme(ColorBrightGreen),
+			LevelOngoing: boldTheme(ColorBrightMagenta),
+			LevelWarning: boldTheme(ColorBrightYellow),
+			LevelError:   boldTheme(ColorBrightRed),
+			LevelFatal: {
+				Border:    Style{Color: ColorBrightRed, Bold: true},
+				Tag:       Style{Color: ColorBrightWhite, Background: ColorRed, Bold: true},
+				Timestamp: Style{Color: ColorBrightWhite, Bold: true},
+				Body:      Style{Color: ColorBrightWhite, Background: ColorRed, Bold: true},
+			},
+		},
+	}
+
+	// ThemeMonochrome uses no colors, only bold tags and bold Error and Fatal text
+	ThemeMonochrome = Theme{
+		Name: "monochrome",
+		Levels: map[Level]LevelTheme{
+			LevelInfo:  {Tag: Style{Bold: true}},
+			LevelError: {Tag: Style{Bold: true}, Body: Style{Bold: true}},
+			LevelFatal: {Border: Style{Bold: true}, Tag: Style{Bold: true, Underline: true}, Body: Style{Bold: true}},
+		},
+	}
+)
+
+// lightTheme styles a level for light backgrounds with a faint timestamp
+func lightTheme(c Color) LevelTheme {
+	lt := solidTheme(c)
+	lt.Timestamp.Faint = true
+	return lt
+}
+
+// boldTheme styles a whole level in bold
+func boldTheme(c Color) LevelTheme {
+	s := Style{Color: c, Bold: true}
+	return LevelTheme{Border: s, Tag: Style{Color: c, Bold: true, Underline: true}, Timestamp: s, Body: s}
+}
+
+// SetTheme sets the colors used to draw boxes
+func (l *Logger) SetTheme(theme Theme) {
+	theme = theme.clone()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.s.theme = theme
+	l.set |= setTheme
+}
+
+// Theme returns the theme used to draw boxes
+func (l *Logger) Theme() Theme {
+	return l.settings().theme.clone()
+}
+
+// SetTheme sets the theme of the default logger
+func SetTheme(theme Theme) {
+	DefaultLogger.SetTheme(theme)
+}
@@ -0,0 +1,293 @@
+package ulog
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ansiPattern matches ANSI CSI sequences (colors, cursor movement) and OSC
+// sequences (e.g. hyperlinks) which take no space on the terminal
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`)
+
+// Zero-width and combining runes that need special handling
+const (
+	zeroWidthJoiner    = '\u200d'
+	variationSelector  = '\ufe0f'
+	regionalIndicatorA = 0x1f1e6
+	regionalIndicatorZ = 0x1f1ff
+	skinToneFirst      = 0x1f3fb
+	skinToneLast       = 0x1f3ff
+)
+
+// wideRanges lists the East Asian Wide and Fullwidth code points, including
+// emoji with default emoji presentation, which take two terminal cells
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f}, {0x231a, 0x231b}, {0x2329, 0x232a}, {0x23e9, 0x23ec},
+	{0x23f0, 0x23f0}, {0x23f3, 0x23f3}, {0x25fd, 0x25fe}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267f, 0x267f}, {0x2693, 0x2693}, {0x26a1, 0x26a1},
+	{0x26aa, 0x26ab}, {0x26bd, 0x26be}, {0x26c4, 0x26c5}, {0x26ce, 0x26ce},
+	{0x26d4, 0x26d4}, {0x26ea, 0x26ea}, {0x26f2, 0x26f3}, {0x26f5, 0x26f5},
+	{0x26fa, 0x26fa}, {0x26fd, 0x26fd}, {0x2705, 0x2705}, {0x270a, 0x270b},
+	{0x2728, 0x2728}, {0x274c, 0x274c}, {0x274e, 0x274e}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27b0, 0x27b0}, {0x27bf, 0x27bf},
+	{0x2b1b, 0x2b1c}, {0x2b50, 0x2b50}, {0x2b55, 0x2b55}, {0x2e80, 0x303e},
+	{0x3041, 0x33ff}, {0x3400, 0x4dbf}, {0x4e00, 0x9fff}, {0xa000, 0xa4cf},
+	{0xa960, 0xa97f}, {0xac00, 0xd7a3}, {0xf900, 0xfaff}, {0xfe10, 0xfe19},
+	{0xfe30, 0xfe6f}, {0xff00, 0xff60}, {0xffe0, 0xffe6}, {0x16fe0, 0x16fe4},
+	{0x17000, 0x18aff}, {0x1b000, 0x1b2ff}, {0x1f004, 0x1f004}, {0x1f0cf, 0x1f0cf},
+	{0x1f18e, 0x1f18e}, {0x1f191, 0x1f19a}, {0x1f200, 0x1f202}, {0x1f210, 0x1f23b},
+	{0x1f240, 0x1f248}, {0x1f250, 0x1f251}, {0x1f260, 0x1f265}, {0x1f300, 0x1f320},
+	{0x1f32d, 0x1f335}, {0x1f337, 0x1f37c}, {0x1f37e, 0x1f393}, {0x1f3a0, 0x1f3ca},
+	{0x1f3cf, 0x1f3d3}, {0x1f3e0, 0x1f3f0}, {0x1f3f4, 0x1f3f4}, {0x1f3f8, 0x1f43e},
+	{0x1f440, 0x1f440}, {0x1f442, 0x1f4fc}, {0x1f4ff, 0x1f53d}, {0x1f54b, 0x1f54e},
+	{0x1f550, 0x1f567}, {0x1f57a, 0x1f57a}, {0x1f595, 0x1f596}, {0x1f5a4, 0x1f5a4},
+	{0x1f5fb, 0x1f64f}, {0x1f680, 0x1f6c5}, {0x1f6cc, 0x1f6cc}, {0x1f6d0, 0x1f6d2},
+	{0x1f6d5, 0x1f6d7}, {0x1f6dc, 0x1f6df}, {0x1f6eb, 0x1f6ec}, {0x1f6f4, 0x1f6fc},
+	{0x1f7e0, 0x1f7eb}, {0x1f7f0, 0x1f7f0}, {0x1f90c, 0x1f93a}, {0x1f93c, 0x1f945},
+	{0x1f947, 0x1f9ff}, {0x1fa70, 0x1faff}, {0x20000, 0x2fffd}, {0x30000, 0x3fffd},
+}
+
+// stripANSI removes ANSI escape sequences from s
+func stripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// isWide reports whether r takes two terminal cells
+func isWide(r rune) bool {
+	i := sort.Search(len(wideRanges), func(i int) bool { return wideRanges[i][1] >= r })
+	return i < len(wideRanges) && wideRanges[i][0] <= r
+}
+
+// isZeroWidth reports whether r takes no space of its own, such as combining
+// accents, variation selectors and other format characters
+func isZeroWidth(r rune) bool {
+	switch {
+	case r == 0:
+		return true
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return true
+	case r >= 0xfe00 && r <= 0xfe0f, r >= 0xe0100 && r <= 0xe01ef:
+		return true
+	case r >= 0x1160 && r <= 0x11ff: // Hangul medial vowels and final consonants
+		return true
+	}
+	return unicode.IsControl(r)
+}
+
+// runeWidth returns the number of terminal cells r takes on its own
+func runeWidth(r rune) int {
+	switch {
+	case isZeroWidth(r):
+		return 0
+	case isWide(r):
+		return 2
+	}
+	return 1
+}
+
+// displayWidth returns the number of terminal cells s takes when printed.
+// ANSI escape sequences are ignored, East Asian wide characters and emoji count
+// as two cells, and combining marks, skin-tone modifiers and characters joined
+// with a zero-width joiner add no width to the preceding character.
+func displayWidth(s string) int {
+	if isASCIIPrintable(s) {
+		return len(s)
+	}
+
+	width := 0
+	prevWidth := 0
+	joined := false
+	regional := false
+	for _, r := range stripANSI(s) {
+		switch {
+		case r == zeroWidthJoiner:
+			joined = true
+			continue
+		case joined:
+			// The rune is drawn as part of the preceding emoji sequence
+			joined = false
+			continue
+		case r == variationSelector:
+			// Emoji presentation widens a preceding narrow symbol, e.g. "❤️"
+			if prevWidth == 1 {
+				width++
+				prevWidth = 2
+			}
+			continue
+		case r >= skinToneFirst && r <= skinToneLast && prevWidth == 2:
+			continue
+		case r >= regionalIndicatorA && r <= regionalIndicatorZ:
+			// Two regional indicators form a single flag
+			if regional {
+				regional = false
+				continue
+			}
+			regional = true
+			width += 2
+			prevWidth = 2
+			continue
+		}
+		regional = false
+		prevWidth = runeWidth(r)
+		width += prevWidth
+	}
+	return width
+}
+
+// tabWidth is the distance between tab stops when tabs are expanded
+const tabWidth = 8
+
+// expandTabs replaces each tab in line with spaces up to the next tab stop, so
+// that lines containing tabs, such as stack traces or tables, can be measured
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var b strings.Builder
+	col := 0
+	for {
+		i := strings.IndexByte(line, '\t')
+		if i < 0 {
+			b.WriteString(line)
+			return b.String()
+		}
+		b.WriteString(line[:i])
+		col += displayWidth(line[:i])
+		n := tabWidth - col%tabWidth
+		b.WriteString(strings.Repeat(" ", n))
+		col += n
+		line = line[i+1:]
+	}
+}
+
+// isASCIIPrintable reports whether s only contains printable ASCII, whose width is its length
+func isASCIIPrintable(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// availableWidth returns the number of columns a box written to w may use.
+// The terminal width is used when w is a terminal, otherwise the COLUMNS
+// environment variable; maxWidth caps either. It returns 0 when no limit is known.
+func availableWidth(w io.Writer, maxWidth int) int {
+	width := terminalWidth(w)
+	if width <= 0 {
+		width, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+	}
+	if maxWidth > 0 && (width <= 0 || maxWidth < width) {
+		width = maxWidth
+	}
+	if width < 0 {
+		return 0
+	}
+	return width
+}
+
+// wrapLine soft-wraps line on spaces so that no piece is wider than width cells.
+// Words wider than width on their own, such as long URLs, are broken mid-word.
+func wrapLine(line string, width int) []string {
+	if width < 1 || displayWidth(line) <= width {
+		return []string{line}
+	}
+
+	var lines []string
+	var current strings.Builder
+	currentWidth := 0
+	started := false
+	for _, word := range strings.Split(line, " ") {
+		wordWidth := displayWidth(word)
+		if started && currentWidth+1+wordWidth <= width {
+			current.WriteString(" ")
+			current.WriteString(word)
+			currentWidth += 1 + wordWidth
+			continue
+		}
+		if started {
+			lines = append(lines, current.String())
+			current.Reset()
+		}
+		for wordWidth > width {
+			head, tail := breakWord(word, width)
+			lines = append(lines, head)
+			word = tail
+			wordWidth = displayWidth(word)
+		}
+		current.WriteString(word)
+		currentWidth = wordWidth
+		started = true
+	}
+	return append(lines, current.String())
+}
+
+// breakWord splits word after at most width cells without splitting escape
+// sequences or multi-rune characters. At least one character is always taken.
+func breakWord(word string, width int) (head, tail string) {
+	used := 0
+	i := 0
+	for i < len(word) {
+		if loc := ansiPattern.FindStringIndex(word[i:]); loc != nil && loc[0] == 0 {
+			i += loc[1]
+			continue
+		}
+		end := clusterEnd(word, i)
+		w := displayWidth(word[i:end])
+		if used+w > width && used > 0 {
+			break
+		}
+		used += w
+		i = end
+	}
+	return word[:i], word[i:]
+}
+
+// truncateWidth shortens s to at most width cells, replacing the cut part with ellipsis
+func truncateWidth(s string, width int, ellipsis string) string {
+	if width < 1 || displayWidth(s) <= width {
+		return s
+	}
+	if room := width - displayWidth(ellipsis); room > 0 {
+		head, _ := breakWord(s, room)
+		return head + ellipsis
+	}
+	head, _ := breakWord(s, width)
+	return head
+}
+
+// clusterEnd returns the end of the character starting at byte offset i,
+// including any combining marks, modifiers and zero-width-joined runes that follow it
+func clusterEnd(s string, i int) int {
+	first, size := utf8.DecodeRuneInString(s[i:])
+	i += size
+	regional := first >= regionalIndicatorA && first <= regionalIndicatorZ
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == zeroWidthJoiner:
+			// Take the joiner together with the rune it joins
+			i += size
+			if i < len(s) {
+				_, next := utf8.DecodeRuneInString(s[i:])
+				i += next
+			}
+			continue
+		case regional && r >= regionalIndicatorA && r <= regionalIndicatorZ:
+			regional = false
+		case r >= skinToneFirst && r <= skinToneLast:
+		case r != 0x1b && isZeroWidth(r):
+		default:
+			return i
+		}
+		i += size
+	}
+	return i
+}
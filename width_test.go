@@ -0,0 +1,159 @@
+package ulog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{"empty", "", 0},
+		{"ascii", "hello world", 11},
+		{"precomposed accent", "café", 4},
+		{"combining accent", "café", 4},
+		{"cjk", "日本語", 6},
+		{"cjk and ascii", "ログ: ok", 8},
+		{"hangul", "한국어", 6},
+		{"fullwidth", "ＡＢ", 4},
+		{"emoji", "🚀", 2},
+		{"emoji and text", "done ✅", 7},
+		{"variation selector", "❤️", 2},
+		{"skin tone", "👍🏽", 2},
+		{"zwj family", "👨‍👩‍👧‍👦", 2},
+		{"zwj profession", "👩‍💻 coding", 9},
+		{"flag", "🇯🇵", 2},
+		{"two flags", "🇯🇵🇫🇷", 4},
+		{"ansi color", "\x1b[31mred\x1b[0m", 3},
+		{"ansi truecolor", "\x1b[38;2;255;0;0m日本\x1b[0m", 4},
+		{"osc hyperlink", "\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", 4},
+		{"zero width space", "a​b", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := displayWidth(tt.in); got != tt.want {
+				t.Errorf("displayWidth(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandTabs(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"no tabs", "no tabs"},
+		{"\tat main.go:42", "        at main.go:42"},
+		{"a\tb", "a       b"},
+		{"name\tvalue\tx", "name    value   x"},
+		{"12345678\tx", "12345678        x"},
+		{"日本\tx", "日本    x"},
+	}
+	for _, tt := range tests {
+		if got := expandTabs(tt.in); got != tt.want {
+			t.Errorf("expandTabs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWrapLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		width int
+		want  []string
+	}{
+		{"no limit", "a long line of text", 0, []string{"a long line of text"}},
+		{"fits", "short", 10, []string{"short"}},
+		{"words", "the quick brown fox jumps", 10, []string{"the quick", "brown fox", "jumps"}},
+		{"url", "see https://example.com/a/very/long/path", 12,
+			[]string{"see", "https://exam", "ple.com/a/ve", "ry/long/path"}},
+		{"accents", "café crème brûlée", 11, []string{"café crème", "brûlée"}},
+		{"cjk", "日本語のテキスト", 6, []string{"日本語", "のテキ", "スト"}},
+		{"cjk odd width", "日本語", 5, []string{"日本", "語"}},
+		{"emoji", "🚀🚀🚀", 4, []string{"🚀🚀", "🚀"}},
+		{"zwj kept whole", "👨‍👩‍👧👨‍👩‍👧", 3, []string{"👨‍👩‍👧", "👨‍👩‍👧"}},
+		{"combining kept whole", "ééé", 2, []string{"éé", "é"}},
+		{"ansi kept whole", "\x1b[31mabcdef\x1b[0m", 3, []string{"\x1b[31mabc", "def\x1b[0m"}},
+		{"wide rune wider than width", "日x", 1, []string{"日", "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapLine(tt.line, tt.width)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrapLine(%q, %d) = %q, want %q", tt.line, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoxBorderAlignment(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	messages := []string{
+		"plain ascii",
+		"accents: café crème brûlée",
+		"combining: café",
+		"cjk: 日本語のログ",
+		"emoji: 🚀 ✅ ❤️ 👍🏽",
+		"zwj: 👨‍👩‍👧‍👦 and 👩‍💻",
+		"flags: 🇯🇵🇫🇷",
+		"ansi: \x1b[1;32mgreen\x1b[0m text",
+		"tabs:\tone\ttwo",
+		"multi\nline 日本\n\tindented",
+	}
+	for _, tag := range []string{"", "TAG", "日本語", "🚀"} {
+		var out recorder
+		l := testLogger(&out)
+		for _, msg := range messages {
+			l.Info(msg, tag)
+			l.With("key", msg).Info("fields", tag)
+		}
+		boxes := splitBoxes(t, out.String())
+		if len(boxes) != 2*len(messages) {
+			t.Fatalf("got %d boxes, want %d", len(boxes), 2*len(messages))
+		}
+		for _, box := range boxes {
+			checkBox(t, box)
+		}
+	}
+}
+
+func TestBoxFitsWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	var out recorder
+	l := testLogger(&out, WithMaxWidth(20))
+	l.Info("the quick brown fox jumps over the lazy dog at https://example.com/a/long/path 日本語のテキスト")
+	for _, box := range splitBoxes(t, out.String()) {
+		checkBox(t, box)
+		if w := displayWidth(box[0]); w > 20 {
+			t.Errorf("box is %d cells wide, want at most 20:\n%s", w, strings.Join(box, "\n"))
+		}
+	}
+}
+
+func TestBoxLongTag(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	tag := strings.Repeat("SUBSYSTEM.", 4) + "DB日本"
+	for _, style := range []BoxStyle{BoxRounded, BoxASCII, BoxNone} {
+		var out recorder
+		l := testLogger(&out, WithMaxWidth(30), WithBoxStyle(style), WithCaller(CallerFunction))
+		l.SetTimestamp(true)
+		l.Info("short", tag)
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		for _, line := range lines {
+			if w := displayWidth(line); w > 30 {
+				t.Errorf("line is %d cells wide, want at most 30:\n%s", w, out.String())
+			}
+		}
+		if style == BoxRounded {
+			checkBox(t, lines)
+			if !strings.Contains(lines[0], "SUBSYSTEM.SUBSYSTEM.S…") {
+				t.Errorf("tag not truncated with an ellipsis: %q", lines[0])
+			}
+		}
+	}
+}